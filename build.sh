@@ -6,4 +6,5 @@ go build -o bin/monolithic ./cmd/monolithic/
 go build -o bin/persistent_tables ./cmd/persistent_tables/
 go build -o bin/pipeline ./cmd/pipeline/
 go build -o bin/quarantine ./cmd/quarantine/
-go build -o bin/things ./cmd/things/
\ No newline at end of file
+go build -o bin/things ./cmd/things/
+go build -o bin/eis ./cmd/eis/
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/bench"
+	"github.com/R0Xps/exercises-in-style-go/internal/browse"
+	"github.com/R0Xps/exercises-in-style-go/internal/cli"
+	"github.com/R0Xps/exercises-in-style-go/internal/compare"
+	"github.com/R0Xps/exercises-in-style-go/internal/failure"
+	"github.com/R0Xps/exercises-in-style-go/internal/repl"
+	"github.com/R0Xps/exercises-in-style-go/internal/styles"
+)
+
+// eis runs any of the styles as a subcommand, with the same options and arguments as the style's own binary
+func main() {
+	if len(os.Args) < 2 {
+		usage(os.Stderr)
+		os.Exit(cli.UsageExitCode)
+	}
+
+	name := os.Args[1]
+	switch name {
+	case "styles":
+		listStyles()
+		return
+	case "-version", "--version":
+		fmt.Println("eis", cli.Version())
+		return
+	case "help", "-h", "-help", "--help":
+		// "eis help <style>" shows the options of the style, "eis help <command>" the ones of the command
+		if len(os.Args) > 2 {
+			name = os.Args[2]
+			if c, ok := findCommand(name); ok {
+				c.main("eis "+c.name, []string{"--help"})
+				return
+			}
+			s, ok := styles.Get(name)
+			if !ok {
+				unknownStyle(name)
+			}
+			cli.Main(s, "eis "+s.Name, []string{"--help"})
+		}
+		usage(os.Stdout)
+		return
+	}
+
+	if c, ok := findCommand(name); ok {
+		c.main("eis "+c.name, os.Args[2:])
+		return
+	}
+	s, ok := styles.Get(name)
+	if !ok {
+		unknownStyle(name)
+	}
+	cli.Main(s, "eis "+s.Name, os.Args[2:])
+}
+
+// A command of eis that isn't a style
+type command struct {
+	name string
+	// The arguments of the command after its options, as shown in the usage message
+	args    string
+	summary string
+	// main runs the command with the given program name and arguments, and exits on failure like cli.Main
+	main func(name string, args []string)
+}
+
+// The commands of eis that aren't styles, in the order of the usage message
+var commands = []command{
+	{
+		name:    "compare",
+		args:    compare.Command.Arguments(),
+		summary: "run the styles on the same inputs, and compare their rankings, time and memory",
+		main:    func(name string, args []string) { cli.Main(compare.Command, name, args) },
+	},
+	{
+		name:    "repl",
+		args:    repl.Command.Arguments(),
+		summary: "read the inputs once, then look up counts, list words and change the stop words at a prompt",
+		main:    func(name string, args []string) { cli.Main(repl.Command, name, args) },
+	},
+	{
+		name:    "browse",
+		args:    browse.Command.Arguments(),
+		summary: "browse the ranking on a full-screen terminal, with examples and stop words lists to toggle",
+		main:    func(name string, args []string) { cli.Main(browse.Command, name, args) },
+	},
+	{
+		name:    "bench",
+		summary: "run the styles on synthetic corpora, and write their throughput and memory as JSON",
+		main: func(name string, args []string) {
+			err := bench.Main(name, args)
+			if err != nil {
+				cli.Exit(name, err)
+			}
+		},
+	},
+}
+
+// Return the command with the given name, if there is one
+func findCommand(name string) (command, bool) {
+	for _, c := range commands {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return command{}, false
+}
+
+// Print that there is no style with the given name, then exit with the usage exit code
+func unknownStyle(name string) {
+	cli.Exit("eis", failure.Wrap(failure.Usage, fmt.Errorf("unknown style %q, run 'eis styles' to list the styles", name)))
+}
+
+// The column the command summaries start at in the usage message
+const summaryColumn = 23
+
+// Print how to use eis to w
+func usage(w io.Writer) {
+	names := make([]string, len(styles.All))
+	for i, s := range styles.All {
+		names[i] = s.Name
+	}
+
+	fmt.Fprintln(w, "usage:")
+	fmt.Fprintln(w, "  eis <style> [options] <stop_words_file> <input_file>...")
+	for _, c := range commands {
+		line := "  eis " + c.name + " [options]"
+		if c.args != "" {
+			line += " " + c.args
+		}
+		// The summary goes on the same line as the usage when it fits before the summaries of the other lines, on the next one otherwise
+		if len(line) < summaryColumn {
+			fmt.Fprintf(w, "%-*s%s\n", summaryColumn, line, c.summary)
+		} else {
+			fmt.Fprintf(w, "%s\n%*s%s\n", line, summaryColumn, "", c.summary)
+		}
+	}
+	fmt.Fprintln(w, "  eis styles           list the styles and the constraints they follow")
+	fmt.Fprintln(w, "  eis help <name>      show the options of a style or of a command")
+	fmt.Fprintln(w, "  eis --version        print the version")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "styles: "+strings.Join(names, ", "))
+}
+
+// Print every style with its title and constraints, as described in its README
+func listStyles() {
+	for i, s := range styles.All {
+		if i > 0 {
+			fmt.Println()
+		}
+		d := styles.Descriptions[s.Name]
+		fmt.Printf("%s - %s\n", s.Name, d.Title)
+		fmt.Printf("  usage: eis %s [options] %s\n", s.Name, s.Arguments())
+		for _, c := range s.Commands {
+			fmt.Printf("         eis %s %s [options] %s\n", s.Name, c.Name, c.Arguments())
+		}
+		for _, c := range d.Constraints {
+			fmt.Printf("  - %s\n", c)
+		}
+	}
+}
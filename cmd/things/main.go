@@ -1,17 +1,14 @@
 package main
 
 import (
-	"log"
 	"os"
+	"path/filepath"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/cli"
+	"github.com/R0Xps/exercises-in-style-go/internal/styles/things"
 )
 
+// The code of the style is in internal/styles/things, so that it can also be run by eis
 func main() {
-	// Check for the required arguments
-	if len(os.Args) != 3 {
-		log.Fatal("required arguments: <stop_words_file> <input_file>")
-	}
-
-	// Initialize an instance of WordFrequencyController with the arguments passed to the program
-	wfc := NewWordFrequencyController(os.Args[1], os.Args[2])
-	wfc.Run()
+	cli.Main(things.Style, filepath.Base(os.Args[0]), os.Args[1:])
 }
@@ -1,14 +1,36 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
 	"io"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/browse"
+	"github.com/R0Xps/exercises-in-style-go/internal/cli"
+	"github.com/R0Xps/exercises-in-style-go/internal/compare"
+	"github.com/R0Xps/exercises-in-style-go/internal/failure"
+	"github.com/R0Xps/exercises-in-style-go/internal/follow"
+	"github.com/R0Xps/exercises-in-style-go/internal/limits"
+	"github.com/R0Xps/exercises-in-style-go/internal/repl"
+	"github.com/R0Xps/exercises-in-style-go/internal/report"
+	"github.com/R0Xps/exercises-in-style-go/internal/styles"
+	"github.com/R0Xps/exercises-in-style-go/internal/styles/actors"
+	"github.com/R0Xps/exercises-in-style-go/internal/tokens"
 )
 
 func TestOutputs(t *testing.T) {
@@ -26,7 +48,8 @@ func TestOutputs(t *testing.T) {
 	for _, inputFile := range inputFiles {
 		inputFilePath := filepath.Join(inputFilesPath, inputFile.Name())
 		for _, item := range items {
-			if item.IsDir() {
+			// Every directory in cmd is a style, except for eis which runs them as subcommands
+			if item.IsDir() && item.Name() != "eis" {
 				func() {
 
 					packagePath := "." + string(os.PathSeparator) + filepath.Join("cmd", item.Name())
@@ -156,6 +179,501 @@ func TestOutputs(t *testing.T) {
 	}
 }
 
+func TestJSONOutput(t *testing.T) {
+	items, err := os.ReadDir("cmd")
+	if err != nil {
+		t.Fatalf("Error reading root directory: %v", err)
+	}
+
+	inputFilePath := filepath.Join("examples", "input", "pride-and-prejudice.txt")
+
+	var expected map[string]any
+	for _, item := range items {
+		if !item.IsDir() || item.Name() == "eis" {
+			continue
+		}
+
+		packagePath := "." + string(os.PathSeparator) + filepath.Join("cmd", item.Name())
+		args := []string{"run", packagePath, "--format", "json", filepath.Join("examples", "stop_words.txt"), inputFilePath}
+		if item.Name() == "persistent_tables" {
+			args = append(args, filepath.Join(t.TempDir(), "test.db"))
+		}
+
+		output, err := exec.Command("go", args...).Output()
+		if err != nil {
+			t.Fatalf("Error running %v: %v", item.Name(), err)
+		}
+
+		var doc map[string]any
+		err = json.Unmarshal(output, &doc)
+		if err != nil {
+			t.Fatalf("Error parsing the output of %v: %v", item.Name(), err)
+		}
+
+		if doc["style"] != item.Name() {
+			t.Errorf("Expected style %q in the output of %v, got %v", item.Name(), item.Name(), doc["style"])
+		}
+		if _, ok := doc["elapsed_ms"].(float64); !ok {
+			t.Errorf("Missing elapsed_ms in the output of %v", item.Name())
+		}
+
+		// Everything else should be the same for every style, except for the order of words with the same frequency
+		delete(doc, "style")
+		delete(doc, "elapsed_ms")
+		entries := doc["entries"].([]any)
+		slices.SortFunc(entries, func(a, b any) int {
+			return strings.Compare(a.(map[string]any)["word"].(string), b.(map[string]any)["word"].(string))
+		})
+		for _, e := range entries {
+			delete(e.(map[string]any), "rank")
+		}
+
+		if expected == nil {
+			expected = doc
+		} else if !reflect.DeepEqual(doc, expected) {
+			t.Errorf("The output of %v is different from the other styles:\n%v\n%v", item.Name(), doc, expected)
+		}
+	}
+}
+
+func TestEIS(t *testing.T) {
+	eis := filepath.Join(t.TempDir(), "eis")
+	err := exec.Command("go", "build", "-o", eis, "./cmd/eis").Run()
+	if err != nil {
+		t.Fatalf("Error building eis: %v", err)
+	}
+
+	output, err := exec.Command(eis, "styles").Output()
+	if err != nil {
+		t.Fatalf("Error running eis styles: %v", err)
+	}
+
+	expected, err := os.ReadFile(filepath.Join("examples", "output", "input1.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every style listed by eis styles should give the same output as its own binary
+	tested := 0
+	for _, line := range strings.Split(string(output), "\n") {
+		name, _, found := strings.Cut(line, " - ")
+		if !found || strings.HasPrefix(line, " ") {
+			continue
+		}
+
+		args := []string{name, filepath.Join("examples", "stop_words.txt"), filepath.Join("examples", "input", "input1.txt")}
+		if name == "persistent_tables" {
+			args = append(args, filepath.Join(t.TempDir(), "test.db"))
+		}
+		stdout, err := exec.Command(eis, args...).Output()
+		if err != nil {
+			t.Fatalf("Error running eis %v: %v", name, err)
+		}
+
+		lines := strings.Split(strings.TrimSpace(string(stdout)), "\n")
+		slices.Sort(lines)
+		if got := strings.Join(lines, "\n") + "\n"; got != string(expected) {
+			t.Errorf("Unexpected output of eis %v:\n%v", name, got)
+		}
+		tested++
+	}
+	if tested == 0 {
+		t.Error("eis styles didn't list any style")
+	}
+
+	// Usage errors exit with code 2
+	err = exec.Command(eis, "monolithic", filepath.Join("examples", "stop_words.txt")).Run()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 2 {
+		t.Errorf("Expected exit code 2 for a missing input file, got %v", err)
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	eis := filepath.Join(t.TempDir(), "eis")
+	err := exec.Command("go", "build", "-o", eis, "./cmd/eis").Run()
+	if err != nil {
+		t.Fatalf("Error building eis: %v", err)
+	}
+
+	stopWords := filepath.Join("examples", "stop_words.txt")
+	input := filepath.Join("examples", "input", "input1.txt")
+	missing := filepath.Join(t.TempDir(), "missing.txt")
+	tests := []struct {
+		args []string
+		code int
+		kind string
+	}{
+		{[]string{stopWords, missing}, 3, "input"},
+		{[]string{stopWords, input, missing}, 3, "input"},
+		{[]string{missing, input}, 4, "stop-words"},
+	}
+
+	for _, s := range styles.All {
+		for _, test := range tests {
+			args := append([]string{s.Name}, test.args...)
+			db := filepath.Join(t.TempDir(), "test.db")
+			if s.Database {
+				args = append(args, db)
+			}
+
+			var stderr strings.Builder
+			cmd := exec.Command(eis, args...)
+			cmd.Stderr = &stderr
+			err := cmd.Run()
+			var exitErr *exec.ExitError
+			if !errors.As(err, &exitErr) || exitErr.ExitCode() != test.code {
+				t.Errorf("eis %v: expected exit code %d, got %v", args, test.code, err)
+			}
+			// The error is a single logfmt line with the kind of the error and the path of the file
+			if !strings.Contains(stderr.String(), "kind="+test.kind+" ") || !strings.Contains(stderr.String(), "path="+missing+" ") || strings.Count(stderr.String(), "\n") != 1 {
+				t.Errorf("eis %v: unexpected error message %q", args, stderr.String())
+			}
+			// A database that couldn't be filled isn't left behind
+			if _, err := os.Stat(db); s.Database && !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("eis %v: the database file was left behind", args)
+			}
+		}
+	}
+
+	// A file that isn't a database is a storage error
+	db := filepath.Join(t.TempDir(), "test.db")
+	err = os.WriteFile(db, []byte("not a database"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = exec.Command(eis, "persistent_tables", stopWords, input, db).Run()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 5 {
+		t.Errorf("Expected exit code 5 for an invalid database, got %v", err)
+	}
+}
+
+func TestInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, s := range styles.All {
+		for _, partial := range []bool{false, true} {
+			filter := tokens.NewFilter()
+			var out bytes.Buffer
+			cfg := &cli.Config{
+				Filter:        filter,
+				Output:        report.NewOptions(filter),
+				StopWordsFile: filepath.Join("examples", "stop_words.txt"),
+				InputFiles:    []string{filepath.Join("examples", "input", "input1.txt"), filepath.Join("examples", "input", "input2.txt")},
+				DatabaseFile:  filepath.Join(t.TempDir(), "test.db"),
+				Stdout:        &out,
+			}
+			cfg.Output.Format = "json"
+			cfg.Output.Partial = partial
+
+			err := s.Run(ctx, cfg)
+			if !errors.Is(err, context.Canceled) || failure.KindOf(err) != failure.Interrupted {
+				t.Errorf("%s: expected an interrupted run, got %v", s.Name, err)
+			}
+
+			// The partial ranking is only printed when it is asked for
+			if !partial {
+				if out.Len() != 0 {
+					t.Errorf("%s: unexpected output without --partial:\n%s", s.Name, out.String())
+				}
+				continue
+			}
+			var doc map[string]any
+			err = json.Unmarshal(out.Bytes(), &doc)
+			if err != nil {
+				t.Errorf("%s: error parsing the partial output: %v\n%s", s.Name, err, out.String())
+			} else if doc["style"] != s.Name {
+				t.Errorf("%s: unexpected style %v in the partial output", s.Name, doc["style"])
+			}
+
+			// No document was stored before the run was interrupted, so the database isn't left behind
+			if _, err := os.Stat(cfg.DatabaseFile); s.Database && !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("%s: the database file was left behind", s.Name)
+			}
+		}
+	}
+}
+
+// cancelAfter is a context that is canceled once Err has been called n times, so a run can be canceled in the middle of its work
+type cancelAfter struct {
+	context.Context
+	n int
+}
+
+func (c *cancelAfter) Err() error {
+	c.n--
+	if c.n < 0 {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestQuarantinePartialCounts(t *testing.T) {
+	s, _ := styles.Get("quarantine")
+	filter := tokens.NewFilter()
+	var out bytes.Buffer
+	cfg := &cli.Config{
+		Filter:        filter,
+		Output:        report.NewOptions(filter),
+		StopWordsFile: filepath.Join("examples", "stop_words.txt"),
+		InputFiles:    []string{filepath.Join("examples", "input", "input1.txt"), filepath.Join("examples", "input", "input2.txt")},
+		Stdout:        &out,
+	}
+	cfg.Output.Format = "json"
+	cfg.Output.Partial = true
+
+	// The context is checked before each of the five functions up to frequencies, then by frequencies before each document,
+	// so the run is canceled after the first document is counted
+	err := s.Run(&cancelAfter{Context: context.Background(), n: 6}, cfg)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected an interrupted run, got %v", err)
+	}
+	var doc struct {
+		Entries []struct {
+			Word      string `json:"word"`
+			Documents []int  `json:"documents"`
+		} `json:"entries"`
+	}
+	err = json.Unmarshal(out.Bytes(), &doc)
+	if err != nil {
+		t.Fatalf("error parsing the partial output: %v\n%s", err, out.String())
+	}
+	if len(doc.Entries) == 0 {
+		t.Fatalf("the words of the first document weren't printed:\n%s", out.String())
+	}
+	for _, e := range doc.Entries {
+		if e.Documents[0] == 0 || e.Documents[1] != 0 {
+			t.Errorf("%s was counted in the documents %v, want only the first one", e.Word, e.Documents)
+		}
+	}
+}
+
+func TestLimits(t *testing.T) {
+	var expected []byte
+	for _, s := range styles.All {
+		for _, policy := range []limits.Policy{limits.Fail, limits.Evict} {
+			filter := tokens.NewFilter()
+			var out bytes.Buffer
+			cfg := &cli.Config{
+				Filter:        filter,
+				Output:        report.NewOptions(filter),
+				Limits:        limits.New(),
+				StopWordsFile: filepath.Join("examples", "stop_words.txt"),
+				InputFiles:    []string{filepath.Join("examples", "input", "pride-and-prejudice.txt")},
+				DatabaseFile:  filepath.Join(t.TempDir(), "test.db"),
+				Stdout:        &out,
+			}
+			cfg.Output.Format = "csv"
+			cfg.Output.Limits = cfg.Limits
+			cfg.Limits.MaxUniqueWords = 500
+			cfg.Limits.Policy = policy
+
+			err := s.Run(context.Background(), cfg)
+			if policy == limits.Fail {
+				if !errors.Is(err, limits.ErrMaxUniqueWords) || failure.KindOf(err) != failure.Limit {
+					t.Errorf("%s: expected a limit error, got %v", s.Name, err)
+				}
+				continue
+			}
+
+			// Every style counts the words in the same order, so they all evict the same words and agree on the ranking
+			if err != nil {
+				t.Errorf("%s: unexpected error while evicting: %v", s.Name, err)
+			} else if expected == nil {
+				expected = out.Bytes()
+			} else if !bytes.Equal(out.Bytes(), expected) {
+				t.Errorf("%s: the ranking is different from the other styles:\n%s\n%s", s.Name, out.Bytes(), expected)
+			}
+		}
+
+		// The input files together are larger than the limit
+		filter := tokens.NewFilter()
+		cfg := &cli.Config{
+			Filter:        filter,
+			Output:        report.NewOptions(filter),
+			Limits:        limits.New(),
+			StopWordsFile: filepath.Join("examples", "stop_words.txt"),
+			InputFiles:    []string{filepath.Join("examples", "input", "input1.txt"), filepath.Join("examples", "input", "input2.txt")},
+			DatabaseFile:  filepath.Join(t.TempDir(), "test.db"),
+			Stdout:        io.Discard,
+		}
+		cfg.Limits.MaxBytes = 100
+		err := s.Run(context.Background(), cfg)
+		if !errors.Is(err, limits.ErrMaxBytes) || failure.KindOf(err) != failure.Limit {
+			t.Errorf("%s: expected a limit error, got %v", s.Name, err)
+		}
+	}
+}
+
+func TestEvictedTokens(t *testing.T) {
+	type counts struct {
+		StopWords int `json:"stop_words_removed"`
+		Evicted   int `json:"evicted_tokens"`
+		Counted   int `json:"counted_tokens"`
+	}
+	run := func(s cli.Style, maxUniqueWords int) counts {
+		filter := tokens.NewFilter()
+		var out bytes.Buffer
+		cfg := &cli.Config{
+			Filter:        filter,
+			Output:        report.NewOptions(filter),
+			Limits:        limits.New(),
+			StopWordsFile: filepath.Join("examples", "stop_words.txt"),
+			InputFiles:    []string{filepath.Join("examples", "input", "pride-and-prejudice.txt")},
+			DatabaseFile:  filepath.Join(t.TempDir(), "test.db"),
+			Stdout:        &out,
+		}
+		cfg.Output.Format = "json"
+		cfg.Output.Limits = cfg.Limits
+		cfg.Limits.MaxUniqueWords = maxUniqueWords
+		cfg.Limits.Policy = limits.Evict
+		err := s.Run(context.Background(), cfg)
+		if err != nil {
+			t.Fatalf("%s: %v", s.Name, err)
+		}
+		var c counts
+		err = json.Unmarshal(out.Bytes(), &c)
+		if err != nil {
+			t.Fatalf("%s: error parsing the output: %v\n%s", s.Name, err, out.String())
+		}
+		return c
+	}
+
+	for _, s := range styles.All {
+		// The occurrences of the evicted words are reported apart from the stop words, and they were counted before they were evicted
+		all := run(s, 0)
+		evicting := run(s, 50)
+		if evicting.Evicted == 0 || evicting.StopWords != all.StopWords || evicting.Evicted+evicting.Counted != all.Counted {
+			t.Errorf("%s: got %+v while evicting words, and %+v without limits", s.Name, evicting, all)
+		}
+	}
+}
+
+func TestMaxUniqueWords(t *testing.T) {
+	var expected []byte
+	for _, s := range styles.All {
+		filter := tokens.NewFilter()
+		var out bytes.Buffer
+		cfg := &cli.Config{
+			Filter:        filter,
+			Output:        report.NewOptions(filter),
+			Limits:        limits.New(),
+			StopWordsFile: filepath.Join("examples", "stop_words.txt"),
+			InputFiles:    []string{filepath.Join("examples", "input", "pride-and-prejudice.txt"), filepath.Join("examples", "input", "input2.txt")},
+			DatabaseFile:  filepath.Join(t.TempDir(), "test.db"),
+			Stdout:        &out,
+		}
+		cfg.Output.Format = "json"
+		cfg.Output.Limits = cfg.Limits
+		cfg.Limits.MaxUniqueWords = 50
+		cfg.Limits.Policy = limits.Evict
+		err := s.Run(context.Background(), cfg)
+		if err != nil {
+			t.Fatalf("%s: %v", s.Name, err)
+		}
+
+		// The limit applies to the words of both input files together, and every style evicts the same words from both of them
+		var doc struct {
+			UniqueWords int `json:"unique_words"`
+			Entries     []struct {
+				Word string `json:"word"`
+			} `json:"entries"`
+		}
+		err = json.Unmarshal(out.Bytes(), &doc)
+		if err != nil {
+			t.Fatalf("%s: error parsing the output: %v\n%s", s.Name, err, out.String())
+		}
+		if doc.UniqueWords != 50 {
+			t.Errorf("%s: got %d unique words, want 50", s.Name, doc.UniqueWords)
+		}
+		var words []byte
+		for _, e := range doc.Entries {
+			words = append(words, e.Word...)
+			words = append(words, ' ')
+		}
+		if expected == nil {
+			expected = words
+		} else if !bytes.Equal(words, expected) {
+			t.Errorf("%s: the ranking is different from the other styles:\n%s\n%s", s.Name, words, expected)
+		}
+	}
+}
+
+// lockedBuffer is a bytes.Buffer that can be read while a style writes to it in another goroutine
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestFollow(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "app.log")
+	err := os.WriteFile(logFile, []byte("darcy darcy bingley\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filter := tokens.NewFilter()
+	var out lockedBuffer
+	cfg := &cli.Config{
+		Filter:        filter,
+		Output:        report.NewOptions(filter),
+		Follow:        &follow.Options{Enabled: true, Interval: 10 * time.Millisecond},
+		StopWordsFile: filepath.Join("examples", "stop_words.txt"),
+		InputFiles:    []string{logFile},
+		Stdout:        &out,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- actors.Style.Run(ctx, cfg)
+	}()
+
+	// Every ranking is printed once its lines are read, and the counts of the appended lines are added to the ones before them
+	waitFor := func(ranking string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for !strings.HasSuffix(out.String(), ranking) {
+			if time.Now().After(deadline) {
+				t.Fatalf("expected the ranking %q, got %q", ranking, out.String())
+			}
+			time.Sleep(cfg.Follow.Interval)
+		}
+	}
+	waitFor("darcy - 2\nbingley - 1\n")
+	file, err := os.OpenFile(logFile, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = file.WriteString("bingley bingley jane\n")
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor("\nbingley - 3\ndarcy - 2\njane - 1\n")
+
+	// Following the files ends when the run is interrupted, which isn't an error
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
 func getRandomDBName() string {
 	randBytes := make([]byte, 16)
 	_, err := rand.Read(randBytes)
@@ -165,3 +683,27 @@ func getRandomDBName() string {
 	}
 	return base64.RawURLEncoding.EncodeToString(randBytes)
 }
+
+func TestConfigOptionsKnown(t *testing.T) {
+	// Every option of every command can be set by a configuration file, which the commands that don't have it ignore
+	commands := []cli.Style{compare.Command, repl.Command, browse.Command}
+	for _, s := range styles.All {
+		commands = append(commands, s)
+		commands = append(commands, s.Commands...)
+	}
+	for _, s := range commands {
+		if s.Flags == nil {
+			continue
+		}
+		fs := flag.NewFlagSet(s.Name, flag.ContinueOnError)
+		s.Flags(fs)
+		fs.VisitAll(func(f *flag.Flag) {
+			if !cli.KnownOption(f.Name) {
+				t.Errorf("%s: the option %s can't be set by a configuration file", s.Name, f.Name)
+			}
+		})
+	}
+	if cli.KnownOption("bogus") || cli.KnownOption("config") {
+		t.Error("unexpected known options")
+	}
+}
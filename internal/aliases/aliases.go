@@ -0,0 +1,71 @@
+// Package aliases reads alias files, which map the different surface forms of a term to a single canonical term
+package aliases
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// Map maps every known surface form (variant) to its canonical term
+type Map map[string]string
+
+// Read the alias file at path and return the Map it describes.
+// Each non-empty line that doesn't start with '#' has the form "canonical: variant variant ...", variants can be separated by spaces or commas.
+// All terms are converted to lowercase, since that's how every style normalizes words
+func Load(path string) (Map, error) {
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+
+	m := make(Map)
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		canonical, variants, found := strings.Cut(line, ":")
+		canonical = strings.ToLower(strings.TrimSpace(canonical))
+		if !found || canonical == "" {
+			return nil, fmt.Errorf("%s:%d: expected \"canonical: variant ...\"", path, lineNumber)
+		}
+
+		fields := strings.FieldsFunc(strings.ToLower(variants), func(r rune) bool {
+			return r == ',' || unicode.IsSpace(r)
+		})
+		for _, variant := range append(fields, canonical) {
+			if other, ok := m[variant]; ok && other != canonical {
+				// A term can only have one canonical term, so chains and cycles of aliases are refused too
+				if other == variant {
+					return nil, fmt.Errorf("%s:%d: %q is already a canonical term", path, lineNumber, variant)
+				}
+				return nil, fmt.Errorf("%s:%d: %q is already an alias of %q", path, lineNumber, variant, other)
+			}
+			m[variant] = canonical
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// Return the canonical term of the given word, or the word itself if it has no alias
+func (m Map) Canonical(word string) string {
+	if canonical, ok := m[word]; ok {
+		return canonical
+	}
+	return word
+}
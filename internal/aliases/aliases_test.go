@@ -0,0 +1,98 @@
+package aliases
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    Map
+		wantErr string
+	}{
+		{
+			name: "syntax",
+			text: "Elizabeth: lizzy, ELIZA\nbennet:bennets\n",
+			want: Map{"elizabeth": "elizabeth", "lizzy": "elizabeth", "eliza": "elizabeth", "bennet": "bennet", "bennets": "bennet"},
+		},
+		{
+			name: "separators",
+			text: "darcy: fitzwilliam,, mr-darcy \t d'arcy",
+			want: Map{"darcy": "darcy", "fitzwilliam": "darcy", "mr-darcy": "darcy", "d'arcy": "darcy"},
+		},
+		{
+			name: "comments and blank lines",
+			text: "# the Bennet family\n\n   \n  # the sisters\njane: jenny\n",
+			want: Map{"jane": "jane", "jenny": "jane"},
+		},
+		{
+			name: "canonical term on two lines",
+			text: "jane: jenny\njane: janey",
+			want: Map{"jane": "jane", "jenny": "jane", "janey": "jane"},
+		},
+		{
+			name: "canonical term without variants",
+			text: "jane:",
+			want: Map{"jane": "jane"},
+		},
+		{
+			name: "self-alias",
+			text: "jane: jane, jenny",
+			want: Map{"jane": "jane", "jenny": "jane"},
+		},
+		{name: "empty file", text: "", want: Map{}},
+		{name: "missing colon", text: "jane jenny", wantErr: `:1: expected "canonical: variant ..."`},
+		{name: "empty canonical term", text: "# comment\n : jenny", wantErr: `:2: expected "canonical: variant ..."`},
+		{name: "two canonical terms", text: "jane: jenny\nlydia: jenny", wantErr: `:2: "jenny" is already an alias of "jane"`},
+		{name: "alias of an alias", text: "jane: jenny\njenny: jen", wantErr: `:2: "jenny" is already an alias of "jane"`},
+		{name: "alias of a canonical term", text: "jenny: jen\njane: jenny", wantErr: `:2: "jenny" is already a canonical term`},
+		{name: "cycle", text: "jane: jenny\njenny: jane", wantErr: `:2: "jane" is already a canonical term`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "aliases.txt")
+			if err := os.WriteFile(path, []byte(test.text), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := Load(path)
+			if test.wantErr != "" {
+				if err == nil || !strings.HasSuffix(err.Error(), test.wantErr) {
+					t.Fatalf("got the error %v, want one ending with %s", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !maps.Equal(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	m := Map{"lizzy": "elizabeth", "elizabeth": "elizabeth"}
+	for word, want := range map[string]string{"lizzy": "elizabeth", "elizabeth": "elizabeth", "darcy": "darcy"} {
+		if got := m.Canonical(word); got != want {
+			t.Errorf("Canonical(%q) = %q, want %q", word, got, want)
+		}
+	}
+	var nilMap Map
+	if got := nilMap.Canonical("lizzy"); got != "lizzy" {
+		t.Errorf("a nil map returned %q", got)
+	}
+}
@@ -0,0 +1,292 @@
+// Package bench measures how the styles scale, by running them on synthetic corpora of different sizes
+package bench
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/cli"
+	"github.com/R0Xps/exercises-in-style-go/internal/compare"
+	"github.com/R0Xps/exercises-in-style-go/internal/failure"
+	"github.com/R0Xps/exercises-in-style-go/internal/report"
+	"github.com/R0Xps/exercises-in-style-go/internal/styles"
+	"github.com/R0Xps/exercises-in-style-go/internal/tokens"
+)
+
+// Options configure a benchmark run
+type Options struct {
+	// Sizes are the sizes of the corpora in bytes, every style is run on a corpus of each size
+	Sizes []int64
+	// Vocabulary, Exponent and Seed are the same for every corpus, see Corpus
+	Vocabulary int
+	Exponent   float64
+	Seed       int64
+	// StopWords is the number of most frequent words of the corpora used as stop words
+	StopWords int
+	// Styles are the styles that are run, in order
+	Styles []cli.Style
+	// Executable is the path of an eis binary, which runs every style again in a process of its own to measure its peak RSS. The RSS isn't measured if it's empty
+	Executable string
+}
+
+// Result is the measurements of a style on a single corpus
+type Result struct {
+	Style      string  `json:"style"`
+	InputBytes int64   `json:"input_bytes"`
+	Tokens     int     `json:"tokens"`
+	Vocabulary int     `json:"vocabulary"`
+	WallMs     float64 `json:"wall_ms"`
+	// BytesPerSec and TokensPerSec are the throughput of the style, computed from the wall time of the in-process run
+	BytesPerSec   float64 `json:"bytes_per_sec"`
+	TokensPerSec  float64 `json:"tokens_per_sec"`
+	Allocs        uint64  `json:"allocs"`
+	AllocBytes    uint64  `json:"alloc_bytes"`
+	PeakHeapBytes uint64  `json:"peak_heap_bytes"`
+	// PeakRSSBytes is the peak resident set size of the process running only this style, it is left out if it isn't measured
+	PeakRSSBytes uint64 `json:"peak_rss_bytes,omitempty"`
+}
+
+// Document is the JSON document written by the bench command, it holds what is needed to compare it with runs on other commits
+type Document struct {
+	Version   string    `json:"version"`
+	GoVersion string    `json:"go_version"`
+	OS        string    `json:"os"`
+	Arch      string    `json:"arch"`
+	CPUs      int       `json:"cpus"`
+	Time      time.Time `json:"time"`
+	Exponent  float64   `json:"exponent"`
+	Seed      int64     `json:"seed"`
+	StopWords int       `json:"stop_words"`
+	Results   []Result  `json:"results"`
+}
+
+// Parse the command-line arguments (without the program name) of the bench command, then run the benchmarks and write their JSON document to stdout.
+// name is the name of the command, which is used in usage messages
+func Main(name string, args []string) error {
+	opts, err := parse(name, args)
+	if err != nil {
+		return failure.Wrap(failure.Usage, err)
+	}
+
+	ctx, stop := cli.SignalContext()
+	defer stop()
+	doc, err := Run(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// Parse the command-line arguments of the bench command, --help prints the usage message and exits
+func parse(name string, args []string) (*Options, error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	opts := &Options{Styles: styles.All}
+	sizes := "1MiB,4MiB"
+	fs.StringVar(&sizes, "sizes", sizes, "generate a corpus of each size in the comma-separated `list`, like 512KiB,1MB")
+	fs.IntVar(&opts.Vocabulary, "vocabulary", 10000, "the number of distinct `words` in the corpora")
+	fs.Float64Var(&opts.Exponent, "exponent", 1.1, "the exponent `s` of the Zipf distribution of the words, it has to be greater than 1")
+	fs.Int64Var(&opts.Seed, "seed", 1, "the seed of the random words of the corpora")
+	fs.IntVar(&opts.StopWords, "stop-words", 25, "use the `n` most frequent words of the corpora as stop words")
+	fs.Func("styles", "run only the comma-separated `list` of styles (default all of them)", func(value string) error {
+		opts.Styles = nil
+		for _, styleName := range strings.Split(value, ",") {
+			s, ok := styles.Get(strings.TrimSpace(styleName))
+			if !ok {
+				return fmt.Errorf("unknown style %q, run 'eis styles' to list the styles", styleName)
+			}
+			opts.Styles = append(opts.Styles, s)
+		}
+		return nil
+	})
+	rss := fs.Bool("rss", true, "run every style again in a process of its own to measure its peak RSS")
+
+	err := fs.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Printf("usage: %s [options]\n\n", name)
+		fmt.Println("Generate synthetic corpora, run the styles on them, and write their throughput, allocations and peak memory as JSON.")
+		fmt.Println()
+		fmt.Println("options:")
+		fs.SetOutput(os.Stdout)
+		fs.PrintDefaults()
+		os.Exit(0)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	for _, size := range strings.Split(sizes, ",") {
+		n, err := humanize.ParseBytes(strings.TrimSpace(size))
+		if err != nil || n == 0 {
+			return nil, fmt.Errorf("invalid size %q", size)
+		}
+		opts.Sizes = append(opts.Sizes, int64(n))
+	}
+	if opts.Vocabulary < 1 {
+		return nil, errors.New("the vocabulary needs at least one word")
+	}
+	if opts.Exponent <= 1 {
+		return nil, errors.New("the exponent has to be greater than 1")
+	}
+
+	if *rss {
+		opts.Executable, err = os.Executable()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return opts, nil
+}
+
+// Generate the corpora, then run every style on each of them and return their measurements. The benchmarks stop with the error of ctx if it is canceled
+func Run(ctx context.Context, opts *Options) (*Document, error) {
+	doc := &Document{
+		Version:   cli.Version(),
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		CPUs:      runtime.NumCPU(),
+		Time:      time.Now().UTC(),
+		Exponent:  opts.Exponent,
+		Seed:      opts.Seed,
+		StopWords: opts.StopWords,
+		Results:   make([]Result, 0),
+	}
+
+	dir, err := os.MkdirTemp("", "eis-bench-")
+	if err != nil {
+		return nil, failure.Wrap(failure.Input, err)
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	for _, size := range opts.Sizes {
+		corpus := Corpus{Size: size, Vocabulary: opts.Vocabulary, Exponent: opts.Exponent, Seed: opts.Seed}
+		stopWordsFile, inputFile, words, err := writeCorpus(dir, corpus, opts.StopWords)
+		if err != nil {
+			return nil, failure.Wrap(failure.Input, err)
+		}
+
+		for _, s := range opts.Styles {
+			result, err := measure(ctx, s, stopWordsFile, inputFile, dir, opts.Executable)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", s.Name, err)
+			}
+			result.Tokens = words
+			result.Vocabulary = corpus.Vocabulary
+			result.TokensPerSec = result.BytesPerSec * float64(words) / float64(result.InputBytes)
+			doc.Results = append(doc.Results, result)
+		}
+	}
+	return doc, nil
+}
+
+// Write the text and the stop words of the corpus to files in dir, and return their paths along with the number of words in the text
+func writeCorpus(dir string, corpus Corpus, stopWords int) (string, string, int, error) {
+	stopWordsFile := filepath.Join(dir, "stop_words.txt")
+	err := os.WriteFile(stopWordsFile, []byte(strings.Join(corpus.StopWords(stopWords), ",")+"\n"), 0o644)
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	inputFile := filepath.Join(dir, fmt.Sprintf("corpus-%d.txt", corpus.Size))
+	file, err := os.Create(inputFile)
+	if err != nil {
+		return "", "", 0, err
+	}
+	words, err := corpus.Write(file)
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	return stopWordsFile, inputFile, words, err
+}
+
+// Run the style on the input file in-process to measure its throughput and allocations, then again in a process of its own to measure its peak RSS if executable is set
+func measure(ctx context.Context, s cli.Style, stopWordsFile, inputFile, dir, executable string) (Result, error) {
+	info, err := os.Stat(inputFile)
+	if err != nil {
+		return Result{}, failure.Wrap(failure.Input, err)
+	}
+
+	filter := tokens.NewFilter()
+	cfg := &cli.Config{
+		Filter:        filter,
+		Output:        report.NewOptions(filter),
+		StopWordsFile: stopWordsFile,
+		InputFiles:    []string{inputFile},
+		Stdout:        io.Discard,
+	}
+	m, err := compare.Measure(ctx, s, cfg)
+	if err != nil {
+		return Result{}, err
+	}
+
+	result := Result{
+		Style:         s.Name,
+		InputBytes:    info.Size(),
+		WallMs:        float64(m.Wall.Microseconds()) / 1000,
+		BytesPerSec:   float64(info.Size()) / m.Wall.Seconds(),
+		Allocs:        m.Allocs,
+		AllocBytes:    m.AllocBytes,
+		PeakHeapBytes: m.PeakHeap,
+	}
+	if executable != "" {
+		result.PeakRSSBytes, err = peakRSS(ctx, s, stopWordsFile, inputFile, dir, executable)
+		if err != nil {
+			return Result{}, err
+		}
+	}
+	return result, nil
+}
+
+// Run the style with the eis binary at executable, and return the peak RSS of its process. The process is killed if ctx is canceled
+func peakRSS(ctx context.Context, s cli.Style, stopWordsFile, inputFile, dir, executable string) (uint64, error) {
+	// An empty configuration file and no EIS_ variables make the style run with the default options, the same way it runs in-process
+	args := []string{s.Name, "--config", os.DevNull, "--format", "json", stopWordsFile, inputFile}
+	if s.Database {
+		db := filepath.Join(dir, s.Name+".db")
+		defer func() {
+			_ = os.Remove(db)
+		}()
+		args = append(args, db)
+	}
+	cmd := exec.CommandContext(ctx, executable, args...)
+	for _, env := range os.Environ() {
+		if !strings.HasPrefix(env, "EIS_") {
+			cmd.Env = append(cmd.Env, env)
+		}
+	}
+	cmd.Stdout = io.Discard
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		return 0, ctx.Err()
+	}
+	if err != nil {
+		return 0, fmt.Errorf("running %s %s: %w: %s", executable, s.Name, err, strings.TrimSpace(stderr.String()))
+	}
+	return maxRSS(cmd.ProcessState), nil
+}
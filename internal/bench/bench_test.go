@@ -0,0 +1,109 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/cli"
+	"github.com/R0Xps/exercises-in-style-go/internal/report"
+	"github.com/R0Xps/exercises-in-style-go/internal/styles"
+	"github.com/R0Xps/exercises-in-style-go/internal/tokens"
+)
+
+func TestCorpus(t *testing.T) {
+	corpus := Corpus{Size: 64 << 10, Vocabulary: 500, Exponent: 1.1, Seed: 1}
+	var first, second bytes.Buffer
+	words, err := corpus.Write(&first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = corpus.Write(&second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(first.Bytes(), second.Bytes()) {
+		t.Error("the same corpus gave two different texts")
+	}
+	if int64(first.Len()) < corpus.Size || int64(first.Len()) > corpus.Size+64 {
+		t.Errorf("got %d bytes, want about %d", first.Len(), corpus.Size)
+	}
+
+	// The words follow the order of the vocabulary, the most frequent word is much more frequent than the tenth
+	counts := make(map[string]int)
+	fields := strings.Fields(strings.ToLower(strings.ReplaceAll(first.String(), ".", "")))
+	for _, word := range fields {
+		counts[word]++
+	}
+	if len(fields) != words {
+		t.Errorf("got %d words, Write returned %d", len(fields), words)
+	}
+	if len(counts) > corpus.Vocabulary {
+		t.Errorf("got %d distinct words, more than the vocabulary", len(counts))
+	}
+	if counts[Word(0)] < 5*counts[Word(9)] {
+		t.Errorf("the first word appears %d times and the tenth %d times", counts[Word(0)], counts[Word(9)])
+	}
+}
+
+func TestRun(t *testing.T) {
+	doc, err := Run(context.Background(), &Options{Sizes: []int64{16 << 10}, Vocabulary: 200, Exponent: 1.2, Seed: 1, StopWords: 5, Styles: styles.All})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Results) != len(styles.All) {
+		t.Fatalf("got %d results, want one for each of the %d styles", len(doc.Results), len(styles.All))
+	}
+	// Every style writes the 25 most frequent of the 200 words as a json report into a buffer while it's measured,
+	// and each entry takes at least the size of {"word":"","count":0}, so the buffer alone allocates that much
+	minAllocBytes := uint64(25 * len(`{"word":"","count":0}`))
+	for _, r := range doc.Results {
+		if r.Tokens == 0 || r.BytesPerSec <= 0 || r.PeakRSSBytes != 0 {
+			t.Errorf("unexpected result %+v", r)
+		}
+		if r.Allocs == 0 || r.AllocBytes < minAllocBytes {
+			t.Errorf("%s allocated %d bytes in %d allocations, want at least %d bytes", r.Style, r.AllocBytes, r.Allocs, minAllocBytes)
+		}
+	}
+}
+
+// Run every style on corpora of increasing size, go test -bench . -benchmem ./internal/bench shows how each of them scales
+func BenchmarkStyles(b *testing.B) {
+	dir := b.TempDir()
+	for _, size := range []int64{64 << 10, 256 << 10, 1 << 20} {
+		corpus := Corpus{Size: size, Vocabulary: 10000, Exponent: 1.1, Seed: 1}
+		stopWordsFile, inputFile, _, err := writeCorpus(dir, corpus, 25)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		for _, s := range styles.All {
+			b.Run(fmt.Sprintf("%s/%dKiB", s.Name, size>>10), func(b *testing.B) {
+				b.SetBytes(size)
+				b.ReportAllocs()
+				for i := 0; b.Loop(); i++ {
+					filter := tokens.NewFilter()
+					cfg := &cli.Config{
+						Filter:        filter,
+						Output:        report.NewOptions(filter),
+						StopWordsFile: stopWordsFile,
+						InputFiles:    []string{inputFile},
+						Stdout:        io.Discard,
+					}
+					// The persistent tables style needs a new database on every run, otherwise it would only read the database
+					if s.Database {
+						cfg.DatabaseFile = filepath.Join(b.TempDir(), fmt.Sprintf("bench-%d.db", i))
+					}
+					err := s.Run(context.Background(), cfg)
+					if err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
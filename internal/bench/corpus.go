@@ -0,0 +1,85 @@
+package bench
+
+import (
+	"bufio"
+	"io"
+	"math/rand/v2"
+	"strings"
+)
+
+// Corpus describes a synthetic text whose word frequencies follow Zipf's law: the word at rank k (starting from 1) appears about as often as k^-Exponent.
+// The same corpus always has the same text
+type Corpus struct {
+	// Size is the size of the text in bytes, the text ends with the first word that reaches it
+	Size int64
+	// Vocabulary is the number of distinct words the text is made of
+	Vocabulary int
+	// Exponent is the exponent of the distribution, it has to be greater than 1, and higher values make the most frequent words more dominant
+	Exponent float64
+	// Seed is the seed of the random numbers that choose the words
+	Seed int64
+}
+
+// The syllables the words of the vocabulary are made of, every word is a different sequence of them
+var (
+	consonants = "bcdfghjklmnprstvwz"
+	vowels     = "aeiou"
+)
+
+// Number of words on a line of the text, the map reduce style splits its input by lines
+const wordsPerLine = 12
+
+// Return the word at the given rank of the vocabulary, where 0 is the most frequent word. Every rank has a different word, made of consonant-vowel syllables
+func Word(rank int) string {
+	syllables := len(consonants) * len(vowels)
+	var b strings.Builder
+	for {
+		s := rank % syllables
+		b.WriteByte(consonants[s/len(vowels)])
+		b.WriteByte(vowels[s%len(vowels)])
+		rank /= syllables
+		if rank == 0 {
+			return b.String()
+		}
+		// Ranks with the same last syllables keep going with the next ones, so the shorter words are the most frequent ones
+		rank--
+	}
+}
+
+// Return the n most frequent words of the corpus, which are used as its stop words like the most common words of a real text
+func (c Corpus) StopWords(n int) []string {
+	words := make([]string, min(n, c.Vocabulary))
+	for i := range words {
+		words[i] = Word(i)
+	}
+	return words
+}
+
+// Write the text of the corpus to w, and return the number of words in it
+func (c Corpus) Write(w io.Writer) (int, error) {
+	rng := rand.New(rand.NewPCG(uint64(c.Seed), uint64(c.Seed)))
+	zipf := rand.NewZipf(rng, c.Exponent, 1, uint64(c.Vocabulary-1))
+
+	bw := bufio.NewWriter(w)
+	written := int64(0)
+	words := 0
+	for written < c.Size {
+		word := Word(int(zipf.Uint64()))
+		// Sentences start with a capital letter, so the text has some of the casing of a real one
+		if words%wordsPerLine == 0 {
+			word = strings.ToUpper(word[:1]) + word[1:]
+		}
+		separator := " "
+		if words%wordsPerLine == wordsPerLine-1 {
+			separator = ".\n"
+		}
+
+		n, err := bw.WriteString(word + separator)
+		if err != nil {
+			return words, err
+		}
+		written += int64(n)
+		words++
+	}
+	return words, bw.Flush()
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package bench
+
+import "os"
+
+// Return the peak resident set size of the process that exited, in bytes. It is only measured on unix systems, so this always returns 0
+func maxRSS(_ *os.ProcessState) uint64 {
+	return 0
+}
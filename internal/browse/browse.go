@@ -0,0 +1,119 @@
+// Package browse shows the ranking of the input files on a full-screen terminal, drawn with ANSI escape sequences only: a table that can be searched and sorted, the lines of the input files the selected word is used in, and stop words lists that can be toggled to count the words again
+package browse
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/cli"
+	"github.com/R0Xps/exercises-in-style-go/internal/failure"
+	"github.com/R0Xps/exercises-in-style-go/internal/styles/things"
+	"github.com/R0Xps/exercises-in-style-go/internal/terminal"
+)
+
+// Command is the browse command, it takes the same options and arguments as the styles, and shows the ranking until q is pressed
+var Command = cli.Style{Name: "browse", Run: run, Flags: registerFlags}
+
+// The options that only browse has
+type options struct {
+	// The stop words lists given with --stop-words-list, which start disabled
+	extraLists []string
+}
+
+// Register the --stop-words-list option, and return the options it sets
+func registerFlags(fs *flag.FlagSet) any {
+	opts := &options{}
+	fs.Func("stop-words-list", "add the stop words `file` to the lists that can be toggled while browsing, it starts disabled (can be repeated)", func(path string) error {
+		opts.extraLists = append(opts.extraLists, path)
+		return nil
+	})
+	return opts
+}
+
+func run(ctx context.Context, cfg *cli.Config) error {
+	width, height, ok := terminal.Size(cfg.Stdout)
+	if !ok || !terminal.IsTerminal(os.Stdin) {
+		return failure.Wrap(failure.Usage, errors.New("browse needs a terminal, use 'eis repl' to read commands from a file or a pipe"))
+	}
+
+	wfc, err := things.NewWordFrequencyController(cfg.StopWordsFile, cfg.InputFiles, cfg.Filter, cfg.Limits, cfg.Output, cfg.Stdout)
+	if err != nil {
+		return err
+	}
+	lists := []stopList{{path: cfg.StopWordsFile, words: wfc.StopWords(), enabled: true}}
+	var extraLists []string
+	if opts, ok := cfg.Options.(*options); ok {
+		extraLists = opts.extraLists
+	}
+	for _, path := range extraLists {
+		swm, err := things.NewStopWordsManager(path, cfg.Filter)
+		if err != nil {
+			return err
+		}
+		lists = append(lists, stopList{path: path, words: swm.Words()})
+	}
+	m := newModel(wfc, newCorpus(cfg.InputFiles, wfc.Texts(), cfg.Filter), cfg.Filter, cfg.Output, lists)
+	err = m.recount(ctx, cfg.Progress)
+	// The progress only shows how the files are loaded, it would be drawn over the screen otherwise
+	cfg.Progress.Stop()
+	if err != nil {
+		return err
+	}
+
+	restore, err := terminal.MakeRaw(os.Stdin)
+	if err != nil {
+		return err
+	}
+	// The browser is drawn on the alternate screen without a cursor, so the terminal is left as it was when it ends
+	fmt.Fprint(cfg.Stdout, "\x1b[?1049h\x1b[?25l")
+	defer func() {
+		fmt.Fprint(cfg.Stdout, "\x1b[?25h\x1b[?1049l")
+		_ = restore()
+	}()
+
+	keys := make(chan string)
+	readErr := make(chan error, 1)
+	go func() {
+		in := bufio.NewReader(os.Stdin)
+		for {
+			key, err := readKey(in)
+			if err != nil {
+				readErr <- err
+				return
+			}
+			keys <- key
+		}
+	}()
+	resized := make(chan os.Signal, 1)
+	terminal.NotifyResize(resized)
+	defer terminal.StopResize(resized)
+
+	for {
+		_, err = io.WriteString(cfg.Stdout, m.draw(width, height))
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-readErr:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		case <-resized:
+			width, height, _ = terminal.Size(cfg.Stdout)
+		case key := <-keys:
+			tableRows, _ := layout(height)
+			if m.handle(ctx, key, tableRows) {
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package browse
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/report"
+	"github.com/R0Xps/exercises-in-style-go/internal/styles/things"
+	"github.com/R0Xps/exercises-in-style-go/internal/tokens"
+)
+
+// Return a new model on the two example inputs, with a second stop words list holding live and mostly
+func newTestModel(t *testing.T) *model {
+	t.Helper()
+	filter := tokens.NewFilter()
+	output := report.NewOptions(filter)
+	stopWords := filepath.Join("..", "..", "examples", "stop_words.txt")
+	inputs := []string{filepath.Join("..", "..", "examples", "input", "input1.txt"), filepath.Join("..", "..", "examples", "input", "input2.txt")}
+	wfc, err := things.NewWordFrequencyController(stopWords, inputs, filter, nil, output, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newCorpus(inputs, wfc.Texts(), filter)
+	lists := []stopList{{path: stopWords, words: wfc.StopWords(), enabled: true}, {path: "extra.txt", words: []string{"live", "mostly"}}}
+	m := newModel(wfc, c, filter, output, lists)
+	err = m.recount(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// Return the words of the table, in order
+func tableWords(m *model) []string {
+	words := make([]string, len(m.rows))
+	for i, row := range m.rows {
+		words[i] = m.ranking.Entries[row].Word
+	}
+	return words
+}
+
+func TestModel(t *testing.T) {
+	m := newTestModel(t)
+	ctx := context.Background()
+	press := func(keys ...string) {
+		for _, key := range keys {
+			if m.handle(ctx, key, 3) {
+				t.Fatalf("%q ended the session", key)
+			}
+		}
+	}
+	if m.selectedWord() != "live" {
+		t.Fatalf("got %q selected, want the first word of the ranking", m.selectedWord())
+	}
+
+	// The search is applied as it's typed, and the selection stays on its word when the order changes
+	press("/", "I", "n", keyEnter, "j")
+	if words := tableWords(m); !slices.Equal(words, []string{"acquaintance", "india"}) {
+		t.Fatalf("got %v after searching for in", words)
+	}
+	selected := m.selectedWord()
+	press("s", "r")
+	if m.selectedWord() != selected || m.order != byWord || !m.reverse {
+		t.Errorf("got %q selected with order %d, want %q", m.selectedWord(), m.order, selected)
+	}
+	press("/", keyEscape)
+	if m.query != "" || len(m.rows) != len(m.ranking.Entries) {
+		t.Errorf("escape didn't clear the search %q", m.query)
+	}
+
+	// Toggling the second list removes its words from the ranking, and toggling it again brings them back
+	n := len(m.ranking.Entries)
+	press("2")
+	if slices.Contains(tableWords(m), "live") || len(m.ranking.Entries) != n-2 {
+		t.Errorf("live is still ranked with the second list on: %v", tableWords(m))
+	}
+	press("2", "9")
+	if !slices.Contains(tableWords(m), "live") || !strings.Contains(m.status, "no stop words list 9") {
+		t.Errorf("live isn't ranked again, status %q", m.status)
+	}
+
+	if !m.handle(ctx, "q", 3) {
+		t.Error("q didn't end the session")
+	}
+}
+
+func TestDraw(t *testing.T) {
+	m := newTestModel(t)
+	m.handle(context.Background(), keyEnd, 3)
+	screen := m.draw(80, 20)
+
+	lines := strings.Split(screen, "\r\n")
+	if len(lines) != 20 {
+		t.Fatalf("got %d lines, want 20", len(lines))
+	}
+	// The selection is scrolled into view, and the detail pane shows where the word is used
+	word := m.selectedWord()
+	for _, want := range []string{" 1 [x] stop_words.txt", "2 [ ] extra.txt (2)", word + " - 1, rank ", "input1.txt:", "input2.txt "} {
+		if !strings.Contains(screen, want) {
+			t.Errorf("the screen doesn't contain %q:\n%s", want, screen)
+		}
+	}
+}
+
+func TestReadKey(t *testing.T) {
+	in := bufio.NewReader(strings.NewReader("a\x1b[A\x1b[6~\x1bOF\x7f\r\x03é"))
+	var keys []string
+	for {
+		key, err := readKey(in)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, key)
+	}
+	want := []string{"a", keyUp, keyPageDown, keyEnd, keyBackspace, keyEnter, keyCtrlC, "é"}
+	if !slices.Equal(keys, want) {
+		t.Errorf("got %q, want %q", keys, want)
+	}
+
+	// An escape that isn't followed by a sequence in the same read is the escape key
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	_, _ = w.Write([]byte{27})
+	_ = w.Close()
+	key, err := readKey(bufio.NewReader(r))
+	if err != nil || key != keyEscape {
+		t.Errorf("got %q, %v, want the escape key", key, err)
+	}
+}
+
+func TestExamples(t *testing.T) {
+	filter := tokens.NewFilter()
+	c := &corpus{inputs: []string{"a.txt"}, texts: [][]byte{[]byte("The cat sat.\nA CAT, a dog;\tcatalog")}, filter: filter}
+	examples := c.examples([]string{"cat"}, 5)
+	if len(examples) != 2 || examples[0].line != 1 || examples[1].line != 2 {
+		t.Fatalf("got examples %+v", examples)
+	}
+	before, word, after := c.context(examples[1], 16)
+	if before != "at. A " || word != "CAT" || after != ", a dog" {
+		t.Errorf("got %q %q %q", before, word, after)
+	}
+	if len(c.examples([]string{"cat"}, 1)) != 1 {
+		t.Error("got more examples than asked for")
+	}
+}
+
+func TestRegisterFlags(t *testing.T) {
+	// Every parse gets its own options, so the lists of a run aren't added to the lists of the one before it
+	first := flag.NewFlagSet("browse", flag.ContinueOnError)
+	opts := registerFlags(first).(*options)
+	if err := first.Parse([]string{"--stop-words-list", "a", "--stop-words-list", "b"}); err != nil {
+		t.Fatal(err)
+	}
+	second := flag.NewFlagSet("browse", flag.ContinueOnError)
+	other := registerFlags(second).(*options)
+	if err := second.Parse([]string{"--stop-words-list", "c"}); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(opts.extraLists, []string{"a", "b"}) || !slices.Equal(other.extraLists, []string{"c"}) {
+		t.Errorf("got the lists %q and %q", opts.extraLists, other.extraLists)
+	}
+}
@@ -0,0 +1,162 @@
+package browse
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// The escape sequences the screen is drawn with
+const (
+	reverseVideo = "\x1b[7m"
+	bold         = "\x1b[1m"
+	resetStyle   = "\x1b[0m"
+	clearLine    = "\x1b[K"
+)
+
+// The number of lines of the screen that aren't the table or the detail pane: the header, the stop words lists, the table header and the footer
+const chromeLines = 4
+
+// Return the number of rows of the table and the number of lines of the detail pane on a screen of the given height. The detail pane takes a third of the lines left by the rest of the screen, and is left out when the screen is too small
+func layout(height int) (int, int) {
+	lines := max(height-chromeLines, 1)
+	if lines < 8 {
+		return lines, 0
+	}
+	detail := min(max(lines/3, 4), 14)
+	return lines - detail, detail
+}
+
+// Return the screen drawn on a terminal of the given size: the header, the table of the words around the selection, the detail pane of the selected word with its examples, and the footer with the keys.
+// Every line starts at the beginning of its row and is cut to the width of the terminal, so the screen can be written over the previous one
+func (m *model) draw(width, height int) string {
+	tableRows, detailLines := layout(height)
+	// The table scrolls to keep the selection in view
+	if m.selected < m.top {
+		m.top = m.selected
+	}
+	if m.selected >= m.top+tableRows {
+		m.top = m.selected - tableRows + 1
+	}
+	m.top = max(min(m.top, len(m.rows)-tableRows), 0)
+
+	var lines []string
+	order := sortNames[m.order]
+	if m.reverse {
+		order += " reversed"
+	}
+	header := fmt.Sprintf(" eis browse  %d of %d words  sorted by %s", len(m.rows), len(m.ranking.Entries), order)
+	if m.query != "" || m.searching {
+		header += "  search: " + m.query
+		if m.searching {
+			header += "_"
+		}
+	}
+	lines = append(lines, reverseVideo+pad(header, width)+resetStyle)
+
+	lists := " stop words:"
+	for i, list := range m.lists {
+		mark := "[ ]"
+		if list.enabled {
+			mark = "[x]"
+		}
+		lists += fmt.Sprintf("  %d %s %s (%d)", i+1, mark, filepath.Base(list.path), len(list.words))
+	}
+	lines = append(lines, cut(lists, width))
+
+	wordWidth := max(min(width-30, 24), 4)
+	lines = append(lines, bold+cut(fmt.Sprintf(" %6s  %-*s %8s %7s", "rank", wordWidth, "word", "count", "share"), width)+resetStyle)
+	for i := m.top; i < m.top+tableRows; i++ {
+		if i >= len(m.rows) {
+			lines = append(lines, "")
+			continue
+		}
+		e := m.ranking.Entries[m.rows[i]]
+		row := cut(fmt.Sprintf(" %6d  %-*s %8d %6.2f%%", m.rows[i]+1, wordWidth, cut(m.ranking.Word(e), wordWidth), e.Freq, share(e.Freq, m.counted)), width)
+		if i == m.selected {
+			row = reverseVideo + pad(row, width) + resetStyle
+		}
+		lines = append(lines, row)
+	}
+
+	lines = append(lines, m.drawDetail(width, detailLines)...)
+
+	footer := " ↑↓ move  PgUp PgDn page  / search  s sort  r reverse  1-9 stop words  q quit"
+	if m.searching {
+		footer = " type to search  Enter keep the search  Esc clear it"
+	}
+	if m.status != "" {
+		footer = " " + m.status
+	}
+	lines = append(lines, reverseVideo+pad(footer, width)+resetStyle)
+
+	var screen strings.Builder
+	screen.WriteString("\x1b[H")
+	for i, line := range lines {
+		if i > 0 {
+			screen.WriteString("\r\n")
+		}
+		screen.WriteString(line + clearLine)
+	}
+	screen.WriteString("\x1b[J")
+	return screen.String()
+}
+
+// Return the lines of the detail pane of the selected word: its count and rank, its count in each input file when there are several of them, and the lines it's used in
+func (m *model) drawDetail(width, lines int) []string {
+	if lines == 0 {
+		return nil
+	}
+	detail := make([]string, 0, lines)
+	word := m.selectedWord()
+	if word == "" {
+		detail = append(detail, bold+pad(" no words", width)+resetStyle)
+	} else {
+		e := m.ranking.Entries[m.rows[m.selected]]
+		title := fmt.Sprintf(" %s - %d, rank %d of %d, %.2f%% of the words counted", m.ranking.Word(e), e.Freq, m.rows[m.selected]+1, len(m.ranking.Entries), share(e.Freq, m.counted))
+		detail = append(detail, bold+reverseVideo+pad(title, width)+resetStyle)
+		if len(e.Documents) > 0 {
+			counts := make([]string, len(e.Documents))
+			for d, freq := range e.Documents {
+				counts[d] = fmt.Sprintf("%s %d", filepath.Base(m.ranking.Inputs[d]), freq)
+			}
+			detail = append(detail, cut(" "+strings.Join(counts, "  "), width))
+		}
+
+		for _, ex := range m.selectedExamples() {
+			if len(detail) == lines {
+				break
+			}
+			location := fmt.Sprintf(" %s:%d  ", filepath.Base(m.corpus.inputs[ex.input]), ex.line)
+			before, match, after := m.corpus.context(ex, width-utf8.RuneCountInString(location))
+			detail = append(detail, cut(location, width)+before+bold+match+resetStyle+after)
+		}
+	}
+	for len(detail) < lines {
+		detail = append(detail, "")
+	}
+	return detail
+}
+
+// Return the percentage of total that freq is
+func share(freq, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(freq) / float64(total)
+}
+
+// Return s cut to at most width runes
+func cut(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	return string([]rune(s)[:max(width, 0)])
+}
+
+// Return s cut or padded with spaces to exactly width runes, so a reversed line fills the whole row
+func pad(s string, width int) string {
+	s = cut(s, width)
+	return s + strings.Repeat(" ", max(width-utf8.RuneCountInString(s), 0))
+}
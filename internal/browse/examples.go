@@ -0,0 +1,85 @@
+package browse
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/tokens"
+)
+
+// corpus holds the text of the input files as it was read, so the examples of a word are shown with their casing and punctuation
+type corpus struct {
+	inputs []string
+	texts  [][]byte
+	filter *tokens.Filter
+}
+
+// example is an occurrence of a word in the corpus: the index of its input file, its line number, and where it starts and ends in the text of the file
+type example struct {
+	input      int
+	line       int
+	start, end int
+}
+
+// Create and return a pointer to a new corpus of the given texts of the input files, the words are found using the token characters of filter.
+// The texts are the ones the style already read, so the input files aren't read again past the limits
+func newCorpus(inputs []string, texts [][]byte, filter *tokens.Filter) *corpus {
+	return &corpus{inputs: inputs, texts: texts, filter: filter}
+}
+
+// Return the first n occurrences of any of the given words in the corpus, in the order of the input files. The words are normalized, so they match whatever their casing is in the text
+func (c *corpus) examples(words []string, n int) []example {
+	examples := make([]example, 0, n)
+	for i, text := range c.texts {
+		line := 1
+		for start := 0; start < len(text) && len(examples) < n; {
+			if !c.filter.IsTokenChar(text[start]) {
+				if text[start] == '\n' {
+					line++
+				}
+				start++
+				continue
+			}
+			end := start
+			for end < len(text) && c.filter.IsTokenChar(text[end]) {
+				end++
+			}
+			token := text[start:end]
+			if slices.ContainsFunc(words, func(word string) bool {
+				return bytes.EqualFold(token, []byte(word))
+			}) {
+				examples = append(examples, example{input: i, line: line, start: start, end: end})
+			}
+			start = end
+		}
+	}
+	return examples
+}
+
+// Return the text around the example on a single line of at most width runes, split into the text before the word, the word, and the text after it. The word is centered when the text around it is long enough
+func (c *corpus) context(e example, width int) (string, string, string) {
+	text := c.texts[e.input]
+	word := printable(text[e.start:e.end])
+	room := max(width-utf8.RuneCountInString(word), 0)
+
+	before := []rune(printable(text[max(e.start-4*room, 0):e.start]))
+	after := []rune(printable(text[e.end:min(e.end+4*room, len(text))]))
+	// The text after the word gets the room the text before it doesn't use, and the other way around
+	left := min(len(before), room/2)
+	right := min(len(after), room-left)
+	left = min(len(before), room-right)
+	return string(before[len(before)-left:]), word, string(after[:right])
+}
+
+// Return the text as a string that can be shown on a single line, with line breaks and other control characters replaced by spaces and invalid UTF-8 replaced by U+FFFD
+func printable(text []byte) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, strings.ToValidUTF8(string(text), "�"))
+}
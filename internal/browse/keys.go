@@ -0,0 +1,93 @@
+package browse
+
+import (
+	"bufio"
+	"unicode"
+)
+
+// The names readKey returns for the keys that don't type a character
+const (
+	keyUp        = "up"
+	keyDown      = "down"
+	keyLeft      = "left"
+	keyRight     = "right"
+	keyPageUp    = "pgup"
+	keyPageDown  = "pgdn"
+	keyHome      = "home"
+	keyEnd       = "end"
+	keyEnter     = "enter"
+	keyEscape    = "esc"
+	keyBackspace = "backspace"
+	keyCtrlC     = "ctrl-c"
+	keyCtrlL     = "ctrl-l"
+	keyCtrlU     = "ctrl-u"
+)
+
+// The keys sent as escape sequences, by the characters that follow "\x1b[" or "\x1bO"
+var escapeKeys = map[string]string{
+	"A":  keyUp,
+	"B":  keyDown,
+	"C":  keyRight,
+	"D":  keyLeft,
+	"H":  keyHome,
+	"F":  keyEnd,
+	"1~": keyHome,
+	"7~": keyHome,
+	"4~": keyEnd,
+	"8~": keyEnd,
+	"5~": keyPageUp,
+	"6~": keyPageDown,
+}
+
+// Read the next key pressed on a terminal in raw mode, and return the character it types, or its name if it doesn't type one.
+// Keys that are neither are returned as an empty string. An escape character that isn't followed by the rest of a sequence in the same read is the escape key itself
+func readKey(in *bufio.Reader) (string, error) {
+	r, _, err := in.ReadRune()
+	if err != nil {
+		return "", err
+	}
+	switch r {
+	case '\r', '\n':
+		return keyEnter, nil
+	case 127, '\b':
+		return keyBackspace, nil
+	case 3:
+		return keyCtrlC, nil
+	case 12:
+		return keyCtrlL, nil
+	case 21:
+		return keyCtrlU, nil
+	case 27:
+		if in.Buffered() == 0 {
+			return keyEscape, nil
+		}
+		return readEscape(in)
+	}
+	if !unicode.IsPrint(r) {
+		return "", nil
+	}
+	return string(r), nil
+}
+
+// Read the rest of an escape sequence, and return the name of its key
+func readEscape(in *bufio.Reader) (string, error) {
+	b, err := in.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	if b != '[' && b != 'O' {
+		return "", nil
+	}
+	// The parameters of the sequence are digits and semicolons, and the sequence ends with the first other character
+	var sequence []byte
+	for {
+		b, err := in.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		sequence = append(sequence, b)
+		if (b < '0' || b > '9') && b != ';' {
+			return escapeKeys[string(sequence)], nil
+		}
+	}
+}
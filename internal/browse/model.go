@@ -0,0 +1,242 @@
+package browse
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/progress"
+	"github.com/R0Xps/exercises-in-style-go/internal/report"
+	"github.com/R0Xps/exercises-in-style-go/internal/styles/things"
+	"github.com/R0Xps/exercises-in-style-go/internal/tokens"
+)
+
+// stopList is a list of stop words that can be toggled while browsing, the words of the enabled lists are the stop words
+type stopList struct {
+	path    string
+	words   []string
+	enabled bool
+}
+
+// The orders the table can be sorted in, s cycles through them
+const (
+	byCount = iota
+	byWord
+	byLength
+	sortOrders
+)
+
+// The names of the sort orders, shown in the header
+var sortNames = [sortOrders]string{"count", "word", "length"}
+
+// model holds the ranking being browsed and the state of the screen, it handles the keys and draws the screen, but doesn't read or write the terminal itself
+type model struct {
+	wfc    *things.WordFrequencyController
+	corpus *corpus
+	// output holds the report options the ranking is processed with, it keeps every word
+	output *report.Options
+	lists  []stopList
+
+	// ranking is the ranking of the words that aren't stop words, and counted is the sum of their counts
+	ranking *report.Report
+	counted int
+
+	// rows holds the indexes in the ranking of the words shown in the table, which match the search and are in the sort order
+	rows      []int
+	query     string
+	searching bool
+	order     int
+	reverse   bool
+	// selected is the index in rows of the selected word, and top is the index in rows of the first word shown
+	selected int
+	top      int
+	// status is a message shown in the footer until the next key
+	status string
+
+	// examples holds the examples of the word they were found for, so they are only looked for once the selection moves
+	examplesOf string
+	examples   []example
+}
+
+// The most examples looked for in the input files
+const maxExamples = 50
+
+// Create and return a pointer to a new model browsing the words counted by wfc, with the examples taken from corpus. The first list is the stop words wfc starts with
+func newModel(wfc *things.WordFrequencyController, corpus *corpus, filter *tokens.Filter, output *report.Options, lists []stopList) *model {
+	all := output.Clone(filter)
+	all.Top = math.MaxInt
+	return &model{wfc: wfc, corpus: corpus, output: all, lists: lists}
+}
+
+// Count the words again with the stop words of the enabled lists, and rank them. The selected word stays selected if it's still in the table
+func (m *model) recount(ctx context.Context, p *progress.Progress) error {
+	var stopWords []string
+	for _, list := range m.lists {
+		if list.enabled {
+			stopWords = append(stopWords, list.words...)
+		}
+	}
+	m.wfc.SetStopWords(stopWords...)
+
+	result, err := m.wfc.Count(ctx, p)
+	if err != nil {
+		return err
+	}
+	ranking, err := report.Process(result, m.output)
+	if err != nil {
+		return err
+	}
+	selected := m.selectedWord()
+	m.ranking = ranking
+	m.counted = ranking.Counted
+	m.update(selected)
+	return nil
+}
+
+// Return the selected word, or an empty string if the table is empty
+func (m *model) selectedWord() string {
+	if m.selected >= len(m.rows) {
+		return ""
+	}
+	return m.ranking.Entries[m.rows[m.selected]].Word
+}
+
+// Find the rows of the table again after the search or the sort order changed, and select the given word if it's still in the table, or the first row otherwise
+func (m *model) update(selected string) {
+	entries := m.ranking.Entries
+	m.rows = m.rows[:0]
+	for i, e := range entries {
+		if strings.Contains(e.Word, m.query) {
+			m.rows = append(m.rows, i)
+		}
+	}
+
+	// The ranking is sorted by count, and the other orders keep the rank of the words with the same word length
+	switch m.order {
+	case byWord:
+		slices.SortFunc(m.rows, func(a, b int) int {
+			return strings.Compare(entries[a].Word, entries[b].Word)
+		})
+	case byLength:
+		slices.SortStableFunc(m.rows, func(a, b int) int {
+			return len(entries[b].Word) - len(entries[a].Word)
+		})
+	}
+	if m.reverse {
+		slices.Reverse(m.rows)
+	}
+
+	m.selected = max(slices.IndexFunc(m.rows, func(i int) bool {
+		return entries[i].Word == selected
+	}), 0)
+}
+
+// Handle a key, and return whether it ends the session. Toggling a stop words list counts the words again, the error doing it is shown in the footer
+func (m *model) handle(ctx context.Context, key string, pageSize int) bool {
+	m.status = ""
+	if m.searching {
+		m.search(key)
+		return false
+	}
+
+	switch key {
+	case "q", keyCtrlC:
+		return true
+	case keyUp, "k":
+		m.move(-1)
+	case keyDown, "j":
+		m.move(1)
+	case keyPageUp:
+		m.move(-pageSize)
+	case keyPageDown, " ":
+		m.move(pageSize)
+	case keyHome, "g":
+		m.move(-len(m.rows))
+	case keyEnd, "G":
+		m.move(len(m.rows))
+	case "/":
+		m.searching = true
+	case "s":
+		m.order = (m.order + 1) % sortOrders
+		m.update(m.selectedWord())
+	case "r":
+		m.reverse = !m.reverse
+		m.update(m.selectedWord())
+	case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+		n, _ := strconv.Atoi(key)
+		m.toggle(ctx, n-1)
+	}
+	return false
+}
+
+// Handle a key typed while searching: the characters are added to the search, which is applied as it's typed. Enter keeps the search and escape clears it
+func (m *model) search(key string) {
+	switch key {
+	case keyEnter:
+		m.searching = false
+		return
+	case keyEscape, keyCtrlC:
+		m.searching = false
+		m.query = ""
+	case keyBackspace:
+		_, size := utf8.DecodeLastRuneInString(m.query)
+		m.query = m.query[:len(m.query)-size]
+	case keyCtrlU:
+		m.query = ""
+	default:
+		if utf8.RuneCountInString(key) != 1 {
+			return
+		}
+		// The words are lowercase, so the search is too
+		m.query += strings.ToLower(key)
+	}
+	m.update(m.selectedWord())
+}
+
+// Move the selection by the given number of rows, staying in the table
+func (m *model) move(rows int) {
+	m.selected = max(min(m.selected+rows, len(m.rows)-1), 0)
+}
+
+// Toggle the stop words list at index i and count the words again
+func (m *model) toggle(ctx context.Context, i int) {
+	if i >= len(m.lists) {
+		m.status = fmt.Sprintf("there is no stop words list %d", i+1)
+		return
+	}
+	m.lists[i].enabled = !m.lists[i].enabled
+	err := m.recount(ctx, nil)
+	if err != nil {
+		m.lists[i].enabled = !m.lists[i].enabled
+		m.status = "error: " + err.Error()
+		return
+	}
+	state := "off"
+	if m.lists[i].enabled {
+		state = "on"
+	}
+	m.status = fmt.Sprintf("%s %s, %d words ranked", filepath.Base(m.lists[i].path), state, len(m.ranking.Entries))
+}
+
+// Return the examples of the selected word, which are looked for in the corpus only once the selection changes.
+// A word that merged variant spellings has the examples of its variants
+func (m *model) selectedExamples() []example {
+	word := m.selectedWord()
+	if word == "" {
+		return nil
+	}
+	if word != m.examplesOf {
+		words := []string{word}
+		for _, v := range m.ranking.Entries[m.rows[m.selected]].Variants {
+			words = append(words, v.Word)
+		}
+		m.examples = m.corpus.examples(words, maxExamples)
+		m.examplesOf = word
+	}
+	return m.examples
+}
@@ -0,0 +1,153 @@
+// Package casing keeps track of how terms are capitalized in the original text, which is lost once the styles convert everything to lowercase
+package casing
+
+import (
+	"strings"
+	"sync"
+)
+
+// Stats holds the casing distribution of a single term
+type Stats struct {
+	// Lower, Title, Upper, and Mixed count the occurrences written as "word", "Word", "WORD", and anything else respectively
+	Lower int
+	Title int
+	Upper int
+	Mixed int
+	// MidSentence counts the occurrences that are not the first word of a sentence, and CapitalizedMidSentence counts how many of those start with an uppercase letter
+	MidSentence            int
+	CapitalizedMidSentence int
+}
+
+// Add the counts of other to s
+func (s *Stats) Add(other Stats) {
+	s.Lower += other.Lower
+	s.Title += other.Title
+	s.Upper += other.Upper
+	s.Mixed += other.Mixed
+	s.MidSentence += other.MidSentence
+	s.CapitalizedMidSentence += other.CapitalizedMidSentence
+}
+
+// Check if the term is probably a proper noun, which is the case when it is capitalized in most of its occurrences in the middle of a sentence
+func (s Stats) IsProperNoun() bool {
+	return s.MidSentence > 0 && s.CapitalizedMidSentence*2 > s.MidSentence
+}
+
+// Return the given lowercase word written in the casing used by most of its occurrences
+func (s Stats) Apply(word string) string {
+	switch max(s.Lower, s.Title, s.Upper) {
+	case s.Lower:
+		return word
+	case s.Title:
+		return strings.ToUpper(word[:1]) + word[1:]
+	default:
+		return strings.ToUpper(word)
+	}
+}
+
+// Scan the given text and return the casing stats of every token in it, keyed by the lowercase token.
+// isTokenChar decides which bytes make up a token, it should be the same function the styles use so the keys match their words
+func Scan(data []byte, isTokenChar func(byte) bool) map[string]*Stats {
+	stats := make(map[string]*Stats)
+	sentenceStart := true
+
+	start := -1
+	for i := 0; i <= len(data); i++ {
+		if i < len(data) && isTokenChar(data[i]) {
+			if start == -1 {
+				start = i
+			}
+			continue
+		}
+
+		if start != -1 {
+			token := string(data[start:i])
+			word := strings.ToLower(token)
+			s, ok := stats[word]
+			if !ok {
+				s = &Stats{}
+				stats[word] = s
+			}
+
+			capitalized := isUpper(token[0])
+			switch {
+			case token == word:
+				s.Lower++
+			case token == strings.ToUpper(word) && len(token) > 1:
+				s.Upper++
+			case capitalized && token[1:] == word[1:]:
+				s.Title++
+			default:
+				s.Mixed++
+			}
+
+			if !sentenceStart {
+				s.MidSentence++
+				if capitalized {
+					s.CapitalizedMidSentence++
+				}
+			}
+
+			sentenceStart = false
+			start = -1
+		}
+
+		if i < len(data) && (data[i] == '.' || data[i] == '!' || data[i] == '?') {
+			sentenceStart = true
+		}
+	}
+
+	return stats
+}
+
+// Add the casing stats in stats to the ones in total, which are keyed by the same lowercase tokens
+func Merge(total, stats map[string]*Stats) {
+	for word, s := range stats {
+		if t, ok := total[word]; ok {
+			t.Add(*s)
+		} else {
+			total[word] = &Stats{}
+			*total[word] = *s
+		}
+	}
+}
+
+// Recorder adds up the casing stats of the texts a run reads, as it reads them, so they match the words it counted even if the files change or are removed afterwards.
+// It is safe for concurrent use, since some styles read their files in other goroutines, and a nil Recorder records nothing
+type Recorder struct {
+	mu    sync.Mutex
+	stats map[string]*Stats
+}
+
+// Create and return a pointer to a new Recorder without any stats
+func NewRecorder() *Recorder {
+	return &Recorder{stats: make(map[string]*Stats)}
+}
+
+// Scan the given text, before it is normalized, and add the casing stats of its tokens to the ones recorded so far. isTokenChar decides which bytes make up a token, like in Scan
+func (r *Recorder) Record(data []byte, isTokenChar func(byte) bool) {
+	if r == nil {
+		return
+	}
+	stats := Scan(data, isTokenChar)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	Merge(r.stats, stats)
+}
+
+// Return a copy of the casing stats recorded so far, keyed by the lowercase token
+func (r *Recorder) Stats() map[string]*Stats {
+	stats := make(map[string]*Stats)
+	if r == nil {
+		return stats
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	Merge(stats, r.stats)
+	return stats
+}
+
+// Check if the given byte is an uppercase ASCII letter
+func isUpper(c byte) bool {
+	return c >= 'A' && c <= 'Z'
+}
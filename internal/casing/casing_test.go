@@ -0,0 +1,33 @@
+package casing
+
+import (
+	"testing"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/tokens"
+)
+
+func TestScan(t *testing.T) {
+	text := "Bennet said the word. The Bennet family, the BENNET estate! Never mind bennet"
+	stats := Scan([]byte(text), tokens.NewFilter().IsTokenChar)
+
+	bennet := *stats["bennet"]
+	want := Stats{Lower: 1, Title: 2, Upper: 1, MidSentence: 3, CapitalizedMidSentence: 2}
+	if bennet != want {
+		t.Errorf("got %+v, want %+v", bennet, want)
+	}
+	if !bennet.IsProperNoun() {
+		t.Error("expected bennet to be a proper noun")
+	}
+	if got := bennet.Apply("bennet"); got != "Bennet" {
+		t.Errorf("got %q, want %q", got, "Bennet")
+	}
+
+	// "The" only ever appears capitalized at the start of a sentence
+	the := *stats["the"]
+	if the.IsProperNoun() {
+		t.Errorf("expected the not to be a proper noun: %+v", the)
+	}
+	if got := the.Apply("the"); got != "the" {
+		t.Errorf("got %q, want %q", got, "the")
+	}
+}
@@ -0,0 +1,390 @@
+// Package cli contains the command-line handling shared by every style, so that each of them can run as its own binary or as a subcommand of eis
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"runtime/debug"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/config"
+	"github.com/R0Xps/exercises-in-style-go/internal/explain"
+	"github.com/R0Xps/exercises-in-style-go/internal/failure"
+	"github.com/R0Xps/exercises-in-style-go/internal/follow"
+	"github.com/R0Xps/exercises-in-style-go/internal/limits"
+	"github.com/R0Xps/exercises-in-style-go/internal/progress"
+	"github.com/R0Xps/exercises-in-style-go/internal/report"
+	"github.com/R0Xps/exercises-in-style-go/internal/tokens"
+	"github.com/R0Xps/exercises-in-style-go/internal/watch"
+)
+
+// UsageExitCode is the exit code of every command when it's called with invalid options or arguments, the exit codes of the other errors are listed in the failure package
+const UsageExitCode = 2
+
+// version is the version printed by --version. It can be set when building with -ldflags "-X github.com/R0Xps/exercises-in-style-go/internal/cli.version=v1.2.3", otherwise the version Go stamped into the binary is used
+var version = ""
+
+// Style is the term frequency program written in one of the styles, or another command that takes the same options and arguments as them
+type Style struct {
+	// Name is the name of the style, which is also the name of its binary and of its eis subcommand
+	Name string
+	// Database is set for styles that take a database file as their last argument
+	Database bool
+	// Follow is set for styles that can keep reading their input files as they grow, with --follow
+	Follow bool
+	// StoredStopWords is set for commands that read the stop words from their database, so they don't take a stop words file,
+	// and StoredInputs for commands that read the documents stored in their database, so they don't take input files either, nor the options of the token filter and the limits on the input
+	StoredStopWords bool
+	StoredInputs    bool
+	// Commands are the subcommands of the style, which are run instead of it when their name is the first argument
+	Commands []Style
+	// Summary describes a subcommand in the usage message of its style
+	Summary string
+	// Explain declares the stages of the style on the plan, so they can be printed with --explain. It is nil for the commands that aren't styles
+	Explain func(p *explain.Plan, cfg *Config)
+	// Run runs the style with the parsed command line, and returns the error that stopped it. The style stops early when ctx is canceled, and returns its error
+	Run func(ctx context.Context, cfg *Config) error
+	// Flags registers the options that only this command has, and returns the value they are parsed into, which the command gets as Config.Options.
+	// It is nil for the styles, which all have the same options
+	Flags func(fs *flag.FlagSet) any
+}
+
+// Config holds everything a style gets from the command line
+type Config struct {
+	// Filter is the token filter every word read by the style goes through
+	Filter *tokens.Filter
+	// Output holds the options the style's results are printed with
+	Output *report.Options
+	// Limits bounds the size of the input and the words the style keeps, it is nil when there are no limits
+	Limits *limits.Limits
+	// Progress receives the checkpoints of the style, it is nil or not enabled when the progress isn't shown
+	Progress *progress.Progress
+	// Watch and Follow hold the options of --watch and --follow, and Explain the plan of the style printed with --explain, they are only set by the command line of the styles
+	Watch         *watch.Options
+	Follow        *follow.Options
+	Explain       *explain.Plan
+	StopWordsFile string
+	InputFiles    []string
+	// DatabaseFile is only set for styles that use a database
+	DatabaseFile string
+	// Options holds the options that only the command has, as returned by its Flags, it is nil for the styles
+	Options any
+	// Stdout is where the style prints its report, which is os.Stdout unless the style is run by another command
+	Stdout io.Writer
+}
+
+// Create and return a pointer to a copy of the config for another run, with a token filter and report options of its own so the runs don't share their counts.
+// The progress is shared, since the runs of a command are shown one after the other on the same progress line
+func (c *Config) Clone() *Config {
+	clone := *c
+	clone.Filter = c.Filter.Clone()
+	clone.Output = c.Output.Clone(clone.Filter)
+	clone.Limits = c.Limits.Clone()
+	clone.Output.Limits = clone.Limits
+	return &clone
+}
+
+// Return the positional arguments the style takes, as they are written in usage messages
+func (s Style) Arguments() string {
+	var args []string
+	if !s.StoredStopWords {
+		args = append(args, "<stop_words_file>")
+	}
+	if !s.StoredInputs {
+		args = append(args, "<input_file>...")
+	}
+	if s.Database {
+		args = append(args, "<database_file>")
+	}
+	return strings.Join(args, " ")
+}
+
+// Return the arguments the style takes when its files are given with their options, as they are written in usage messages
+func (s Style) flagArguments() string {
+	var args []string
+	if !s.StoredStopWords {
+		args = append(args, "--stop-words <file>")
+	}
+	if s.Database {
+		args = append(args, "--db <file>")
+	}
+	if !s.StoredInputs {
+		args = append(args, "<input_file>...")
+	}
+	return strings.Join(args, " ")
+}
+
+// Return the version of the program
+func Version() string {
+	if version != "" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "devel"
+}
+
+// Print the usage message of the style to w, name is the name of the command
+func printUsage(w io.Writer, s Style, name string, fs *flag.FlagSet) {
+	fmt.Fprintf(w, "usage: %s [options] %s\n", name, s.Arguments())
+	fmt.Fprintf(w, "       %s [options] %s\n", name, s.flagArguments())
+	for _, c := range s.Commands {
+		fmt.Fprintf(w, "       %s %s [options] %s\n", name, c.Name, c.Arguments())
+	}
+	if len(s.Commands) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "commands (run '%s <command> --help' for their options):\n", name)
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, c := range s.Commands {
+			fmt.Fprintf(tw, "  %s\t%s\n", c.Name, c.Summary)
+		}
+		_ = tw.Flush()
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "options:")
+	fs.SetOutput(w)
+	fs.PrintDefaults()
+	if s.Flags == nil && s.Explain == nil {
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "%s has no stages to explain, so --explain and --explain-only are a usage error with it\n", s.Name)
+	}
+}
+
+// Print the error to stderr in the format of failure.Print, then exit with the exit code of its kind. name is the name of the command, usage errors also get a hint on how to get its usage message
+func Exit(name string, err error) {
+	kind := failure.KindOf(err)
+	hint := ""
+	if kind == failure.Usage {
+		hint = fmt.Sprintf("run '%s --help' for usage", name)
+	}
+	failure.Print(os.Stderr, name, err, hint)
+	os.Exit(kind.ExitCode())
+}
+
+// Parse the given command-line arguments (without the program name), then run the style with them. name is the name of the command, which is used in usage and error messages.
+// --help prints the usage message and --version prints the version, invalid options or arguments make the program exit with UsageExitCode, and errors returned by the style make it exit with the code of their kind
+func Main(s Style, name string, args []string) {
+	if len(args) > 0 {
+		for _, c := range s.Commands {
+			if args[0] == c.Name {
+				Main(c, name+" "+c.Name, args[1:])
+				return
+			}
+		}
+	}
+
+	cfg, err := parse(s, name, args)
+	if err != nil {
+		Exit(name, failure.Wrap(failure.Usage, err))
+	}
+
+	ctx, stop := SignalContext()
+	defer stop()
+	if cfg.Watch != nil && cfg.Watch.Enabled {
+		err = watchRuns(ctx, s, name, cfg)
+	} else if cfg.Explain.Explained() {
+		err = explainRun(ctx, s, name, cfg)
+	} else {
+		err = run(ctx, s, name, cfg)
+	}
+	if err != nil {
+		Exit(name, err)
+	}
+}
+
+// Run the style once with the config, within its timeout, and return the error that stopped it. name is the name of the command, which is shown with the progress
+func run(ctx context.Context, s Style, name string, cfg *Config) error {
+	ctx, cancel := cfg.Limits.WithTimeout(ctx)
+	defer cancel()
+	// The progress is printed on stderr until the report is printed
+	cfg.Progress.Start(os.Stderr, name, cfg.InputFiles)
+	cfg.Stdout = cfg.Progress.Writer(cfg.Stdout)
+	err := s.Run(ctx, cfg)
+	cfg.Progress.Stop()
+	// A run stopped by its timeout fails with the cause of the timeout, which is clearer than the error of the context
+	if ctx.Err() != nil && errors.Is(err, ctx.Err()) && context.Cause(ctx) != ctx.Err() {
+		err = context.Cause(ctx)
+	}
+	return err
+}
+
+// Return a context that is canceled by the first SIGINT or SIGTERM the program gets, so it can stop cleanly.
+// The signals are only caught once, a second one kills the program as usual in case it doesn't stop quickly enough
+func SignalContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
+
+// Register the options of the style on the flag set, which set the fields of cfg: its files, the token filter, the report options, the limits and the progress,
+// then the options that only the style has, or the options of --watch, --follow and --explain for the styles that don't have options of their own
+func registerOptions(fs *flag.FlagSet, s Style, cfg *Config) {
+	if !s.StoredStopWords {
+		fs.StringVar(&cfg.StopWordsFile, "stop-words", "", "read the stop words from `file`, instead of taking it as the first argument")
+	}
+	if s.Database {
+		fs.StringVar(&cfg.DatabaseFile, "db", "", "use the database `file`, instead of taking it as the last argument")
+	}
+
+	// The token filter decides which characters make up a word, and which words are counted.
+	// The commands that read the stored documents don't count any words, the words went through the filter when they were stored, so they don't take its options
+	cfg.Filter = tokens.NewFilter()
+	if !s.StoredInputs {
+		cfg.Filter.RegisterFlags(fs)
+	}
+	// The report options decide how the final list is printed
+	cfg.Output = report.NewOptions(cfg.Filter)
+	cfg.Output.RegisterFlags(fs)
+	// The limits bound the resources a run can use, only the timeout applies to the commands that don't read input files
+	cfg.Limits = limits.New()
+	if s.StoredInputs {
+		cfg.Limits.RegisterTimeoutFlag(fs)
+	} else {
+		cfg.Limits.RegisterFlags(fs)
+	}
+	cfg.Output.Limits = cfg.Limits
+	// The progress shows how far a long run has got
+	cfg.Progress = progress.New()
+	cfg.Progress.RegisterFlags(fs)
+	if s.Flags != nil {
+		cfg.Options = s.Flags(fs)
+	} else {
+		// Only the styles can be watched or follow their input files, the other commands don't print a ranking of their own
+		cfg.Watch = watch.New()
+		cfg.Watch.RegisterFlags(fs)
+		cfg.Follow = follow.New()
+		cfg.Follow.RegisterFlags(fs)
+		cfg.Explain = explain.New()
+		cfg.Explain.RegisterFlags(fs)
+	}
+}
+
+// Parse the given command-line arguments (without the program name) of the style, and return the config it runs with.
+// --help and --version are handled by printing to stdout and exiting
+func parse(s Style, name string, args []string) (*Config, error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	// Parse would print its errors and the usage message to stderr, but the errors are printed by the caller and the usage message is only printed when asked for
+	fs.SetOutput(io.Discard)
+
+	cfg := &Config{Stdout: os.Stdout}
+	showVersion := fs.Bool("version", false, "print the version and exit")
+	fs.String("config", "", "read default options from the configuration `file`, instead of .eis.toml in the current directory or the user configuration directory")
+	showConfig := fs.Bool("print-config", false, "print the effective options as a configuration file and exit")
+	registerOptions(fs, s, cfg)
+
+	// The options are set from the configuration file first, then from the command line, then from the environment, so each of them overrides the ones before it
+	sources := make(map[string]string)
+	path := explicitConfig(args)
+	if path == "" {
+		var err error
+		path, err = config.Find()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if path != "" {
+		err := applyConfigFile(fs, path, sources)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := fs.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		printUsage(os.Stdout, s, name, fs)
+		os.Exit(0)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, option := range commandLineOptions(fs, args) {
+		sources[option] = "command line"
+	}
+	if *showVersion {
+		fmt.Println(name, Version())
+		os.Exit(0)
+	}
+
+	err = applyEnvironment(fs, sources)
+	if err != nil {
+		return nil, err
+	}
+	if *showConfig {
+		printConfig(os.Stdout, fs, sources)
+		os.Exit(0)
+	}
+
+	err = cfg.Output.Validate()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Watch != nil && cfg.Watch.Enabled {
+		if s.Database {
+			return nil, fmt.Errorf("--watch can't be used with %s, which only reads the input files when it creates the database", s.Name)
+		}
+		if cfg.Output.Format != "plain" {
+			return nil, fmt.Errorf("--watch can only write the plain format, not %q", cfg.Output.Format)
+		}
+	}
+	if cfg.Follow != nil && cfg.Follow.Enabled {
+		if !s.Follow {
+			return nil, fmt.Errorf("--follow can't be used with %s, only the actors style keeps reading its input files", s.Name)
+		}
+		if cfg.Watch.Enabled {
+			return nil, errors.New("--follow and --watch can't be used together")
+		}
+	}
+
+	if cfg.Explain.Explained() {
+		if s.Explain == nil {
+			return nil, fmt.Errorf("--explain can't be used with %s, which has no plan to explain", s.Name)
+		}
+		if cfg.Watch.Enabled || cfg.Follow.Enabled {
+			return nil, errors.New("--explain can't be used with --watch or --follow")
+		}
+		if cfg.Explain.Measured() && cfg.Output.Format != "plain" {
+			return nil, fmt.Errorf("--explain prints the plan before the report, so it can only write the plain format, not %q", cfg.Output.Format)
+		}
+	}
+
+	// The paths that weren't given with their flags are taken from the positional arguments: the stop words file first, and the database last
+	positional := fs.Args()
+	if !s.StoredStopWords && cfg.StopWordsFile == "" {
+		if len(positional) == 0 {
+			return nil, errors.New("missing stop words file")
+		}
+		cfg.StopWordsFile = positional[0]
+		positional = positional[1:]
+	}
+	if s.Database && cfg.DatabaseFile == "" {
+		if s.StoredInputs && len(positional) == 0 {
+			return nil, errors.New("missing database file")
+		}
+		if !s.StoredInputs && len(positional) < 2 {
+			return nil, errors.New("missing database file, it has to come after the input files")
+		}
+		cfg.DatabaseFile = positional[len(positional)-1]
+		positional = positional[:len(positional)-1]
+	}
+	if s.StoredInputs && len(positional) > 0 {
+		return nil, fmt.Errorf("unexpected arguments %q, %s reads the documents stored in the database", positional, s.Name)
+	}
+	if !s.StoredInputs && len(positional) == 0 {
+		return nil, errors.New("missing input files")
+	}
+	cfg.InputFiles = positional
+	cfg.Output.StopWordsFile = cfg.StopWordsFile
+
+	return cfg, nil
+}
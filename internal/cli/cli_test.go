@@ -0,0 +1,218 @@
+package cli
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/explain"
+)
+
+func TestParseArguments(t *testing.T) {
+	style := Style{Name: "test"}
+	database := Style{Name: "test", Database: true}
+	add := Style{Name: "add", Database: true, StoredStopWords: true}
+	list := Style{Name: "list", Database: true, StoredStopWords: true, StoredInputs: true}
+
+	tests := []struct {
+		style     Style
+		args      []string
+		stopWords string
+		inputs    []string
+		db        string
+	}{
+		{style, []string{"stop.txt", "a.txt", "b.txt"}, "stop.txt", []string{"a.txt", "b.txt"}, ""},
+		{style, []string{"--stop-words", "stop.txt", "a.txt"}, "stop.txt", []string{"a.txt"}, ""},
+		{database, []string{"stop.txt", "a.txt", "b.txt", "test.db"}, "stop.txt", []string{"a.txt", "b.txt"}, "test.db"},
+		{database, []string{"--db", "test.db", "--stop-words", "stop.txt", "a.txt"}, "stop.txt", []string{"a.txt"}, "test.db"},
+		{database, []string{"--db", "test.db", "stop.txt", "a.txt"}, "stop.txt", []string{"a.txt"}, "test.db"},
+		{add, []string{"a.txt", "b.txt", "test.db"}, "", []string{"a.txt", "b.txt"}, "test.db"},
+		{add, []string{"--db", "test.db", "a.txt"}, "", []string{"a.txt"}, "test.db"},
+		{list, []string{"test.db"}, "", []string{}, "test.db"},
+		{list, []string{"--db", "test.db"}, "", []string{}, "test.db"},
+		{list, []string{"--timeout", "1m", "test.db"}, "", []string{}, "test.db"},
+	}
+	for _, test := range tests {
+		cfg, err := parse(test.style, "test", test.args)
+		if err != nil {
+			t.Errorf("%v: %v", test.args, err)
+			continue
+		}
+		if cfg.StopWordsFile != test.stopWords || !reflect.DeepEqual(cfg.InputFiles, test.inputs) || cfg.DatabaseFile != test.db {
+			t.Errorf("%v: got stop words %q, inputs %q and database %q", test.args, cfg.StopWordsFile, cfg.InputFiles, cfg.DatabaseFile)
+		}
+	}
+}
+
+func TestParseUsageErrors(t *testing.T) {
+	style := Style{Name: "test"}
+	database := Style{Name: "test", Database: true}
+	explained := Style{Name: "test", Explain: func(*explain.Plan, *Config) {}}
+	add := Style{Name: "add", Database: true, StoredStopWords: true}
+	list := Style{Name: "list", Database: true, StoredStopWords: true, StoredInputs: true}
+
+	tests := []struct {
+		style Style
+		args  []string
+	}{
+		{style, []string{}},
+		{style, []string{"stop.txt"}},
+		{style, []string{"--stop-words", "stop.txt"}},
+		{style, []string{"--unknown", "stop.txt", "a.txt"}},
+		{style, []string{"--top", "0", "stop.txt", "a.txt"}},
+		{style, []string{"--only-proper", "--exclude-proper", "stop.txt", "a.txt"}},
+		{style, []string{"--db", "test.db", "stop.txt", "a.txt"}},
+		{database, []string{"stop.txt", "test.db"}},
+		{database, []string{"--watch", "stop.txt", "a.txt", "test.db"}},
+		{style, []string{"--watch", "--format", "json", "stop.txt", "a.txt"}},
+		{style, []string{"--follow", "stop.txt", "a.txt"}},
+		{Style{Name: "test", Follow: true}, []string{"--follow", "--watch", "stop.txt", "a.txt"}},
+		{style, []string{"--explain", "stop.txt", "a.txt"}},
+		{explained, []string{"--explain", "--watch", "stop.txt", "a.txt"}},
+		{explained, []string{"--explain", "--format", "csv", "stop.txt", "a.txt"}},
+		{add, []string{"test.db"}},
+		{add, []string{"--stop-words", "stop.txt", "a.txt", "test.db"}},
+		{list, []string{}},
+		{list, []string{"a.txt", "test.db"}},
+		// The words of the stored documents already went through the token filter and the limits, so their options are refused instead of being ignored
+		{list, []string{"--numbers", "test.db"}},
+		{list, []string{"--min-length", "7", "test.db"}},
+		{list, []string{"--token-pattern", "[a-z]+", "test.db"}},
+		{list, []string{"--max-bytes", "1KB", "test.db"}},
+		{list, []string{"--max-unique-words", "2", "test.db"}},
+	}
+	for _, test := range tests {
+		_, err := parse(test.style, "test", test.args)
+		if err == nil {
+			t.Errorf("%v: expected a usage error", test.args)
+		}
+	}
+}
+
+func TestPrintUsage(t *testing.T) {
+	// The styles without a plan still register --explain, so their usage message says why it can't be used
+	for _, test := range []struct {
+		style Style
+		note  bool
+	}{
+		{Style{Name: "test"}, true},
+		{Style{Name: "test", Explain: func(*explain.Plan, *Config) {}}, false},
+	} {
+		var out strings.Builder
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		registerOptions(fs, test.style, &Config{})
+		printUsage(&out, test.style, "test", fs)
+		if note := strings.Contains(out.String(), "test has no stages to explain"); note != test.note {
+			t.Errorf("got the note %v in the usage message:\n%s", note, out.String())
+		}
+	}
+}
+
+func TestParseConfigPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	// Relative paths in the configuration file are relative to its directory
+	err := os.WriteFile(filepath.Join(dir, "eis.toml"), []byte("stop-words = \"stop.txt\"\ntop = 5\nmin-length = 2\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvName("min-length"), "4")
+
+	cfg, err := parse(Style{Name: "test"}, "test", []string{"--config", filepath.Join(dir, "eis.toml"), "--top", "10", "--min-length", "3", "a.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.StopWordsFile != filepath.Join(dir, "stop.txt") {
+		t.Errorf("got stop words %q from the configuration file", cfg.StopWordsFile)
+	}
+	// The command line overrides the configuration file, and the environment overrides both
+	if cfg.Output.Top != 10 {
+		t.Errorf("got top %d, want 10 from the command line", cfg.Output.Top)
+	}
+	if cfg.Filter.MinLength != 4 {
+		t.Errorf("got min-length %d, want 4 from the environment", cfg.Filter.MinLength)
+	}
+}
+
+func TestParseConfigUnknownOption(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "eis.toml")
+	err := os.WriteFile(path, []byte("bogus = 1\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parse(Style{Name: "test"}, "test", []string{"--config=" + path, "stop.txt", "a.txt"}); err == nil {
+		t.Error("expected an error for an unknown option")
+	}
+}
+
+func TestParseSharedConfig(t *testing.T) {
+	// A project configuration file is read by every command, so each of them only sets the options it has out of it
+	path := filepath.Join(t.TempDir(), "eis.toml")
+	err := os.WriteFile(path, []byte("stop-words = \"stop.txt\"\nfollow-interval = \"3s\"\nstyles = \"monolithic\"\ndocuments = \"1\"\ntop = 5\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var documents string
+	rank := Style{Name: "rank", Database: true, StoredStopWords: true, StoredInputs: true, Flags: func(fs *flag.FlagSet) any {
+		fs.StringVar(&documents, "documents", "", "the `list` of documents")
+		return nil
+	}}
+
+	cfg, err := parse(rank, "rank", []string{"--config", path, "test.db"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if documents != "1" || cfg.Output.Top != 5 || cfg.StopWordsFile != "" {
+		t.Errorf("rank got the documents %q, top %d and the stop words %q", documents, cfg.Output.Top, cfg.StopWordsFile)
+	}
+
+	cfg, err = parse(Style{Name: "test", Follow: true}, "test", []string{"--config", path, "a.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.StopWordsFile != filepath.Join(filepath.Dir(path), "stop.txt") || cfg.Follow.Interval != 3*time.Second || cfg.Output.Top != 5 {
+		t.Errorf("the style got the stop words %q, the interval %v and top %d", cfg.StopWordsFile, cfg.Follow.Interval, cfg.Output.Top)
+	}
+
+	// Options only the command line has are still refused
+	err = os.WriteFile(path, []byte("print-config = true\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parse(rank, "rank", []string{"--config", path, "test.db"}); err == nil {
+		t.Error("expected an error for an option of the command line")
+	}
+}
+
+func TestParseOptions(t *testing.T) {
+	// Every parse gets options of its own, so a run doesn't see the options of the runs before it
+	s := Style{Name: "test", Flags: func(fs *flag.FlagSet) any {
+		return fs.String("name", "", "a `name`")
+	}}
+	first, err := parse(s, "test", []string{"--name", "first", "stop.txt", "a.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := parse(s, "test", []string{"stop.txt", "a.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := *first.Options.(*string); name != "first" {
+		t.Errorf("got the name %q, want %q", name, "first")
+	}
+	if name := *second.Options.(*string); name != "" {
+		t.Errorf("the second parse got the name %q", name)
+	}
+
+	// The styles have no options of their own
+	cfg, err := parse(Style{Name: "test"}, "test", []string{"stop.txt", "a.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Options != nil {
+		t.Errorf("a style got the options %v", cfg.Options)
+	}
+}
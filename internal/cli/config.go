@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/config"
+)
+
+// Options that only make sense on the command line, so they can't be set by configuration files or environment variables
+var commandLineOnly = []string{"config", "print-config", "version"}
+
+// Options that only some commands register with their Flags, a configuration file can set them since it is shared by every command, and the commands that don't have them ignore them
+var commandOptions = []string{"documents", "history", "stop-words-list", "styles"}
+
+// Check if some style or command has the option with the given name, which are the options a configuration file can set
+func KnownOption(name string) bool {
+	if slices.Contains(commandLineOnly, name) {
+		return false
+	}
+	if slices.Contains(commandOptions, name) {
+		return true
+	}
+	// A style with a database registers every option the styles share
+	fs := flag.NewFlagSet("", flag.ContinueOnError)
+	registerOptions(fs, Style{Database: true}, &Config{})
+	return fs.Lookup(name) != nil
+}
+
+// Options whose values are paths. Relative paths in configuration files are relative to the directory of the file, so the same file works from anywhere
+var pathOptions = []string{"stop-words", "db", "aliases", "template"}
+
+// Return the name of the environment variable that overrides the option with the given name, for example EIS_MIN_LENGTH for min-length
+func EnvName(option string) string {
+	return "EIS_" + strings.ToUpper(strings.ReplaceAll(option, "-", "_"))
+}
+
+// Return the path given with --config in the arguments, or an empty string if there is none.
+// The configuration file has to be read before the other options are parsed, so that the command line overrides it
+func explicitConfig(args []string) string {
+	for i, arg := range args {
+		if arg == "--" || !strings.HasPrefix(arg, "-") {
+			break
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if name != "config" {
+			continue
+		}
+		if hasValue {
+			return value
+		}
+		if i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// Return the names of the options given in the arguments that the flag set parsed, which are all the arguments before the positional ones.
+// This can't be done with Visit, since it also visits the options set from the configuration file
+func commandLineOptions(fs *flag.FlagSet, args []string) []string {
+	names := make([]string, 0)
+	parsed := args[:len(args)-fs.NArg()]
+	for i := 0; i < len(parsed); i++ {
+		name, _, hasValue := strings.Cut(strings.TrimLeft(parsed[i], "-"), "=")
+		f := fs.Lookup(name)
+		if f == nil {
+			continue
+		}
+		names = append(names, name)
+		// Options that aren't booleans take the next argument as their value, unless it is given after '='
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); !hasValue && !(ok && b.IsBoolFlag()) {
+			i++
+		}
+	}
+	return names
+}
+
+// Set the options of the flag set from the configuration file at the given path, and record the file as their source
+func applyConfigFile(fs *flag.FlagSet, path string, sources map[string]string) error {
+	settings, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+
+	// The options are set in alphabetical order, so that options that change others (like template, which changes the format) always end up the same way
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		value := settings[key]
+		if !KnownOption(key) {
+			return fmt.Errorf("%s: unknown option %q", path, key)
+		}
+		// The file is read by every command, so it can set options this one doesn't have, like the ones of compare or the stop words file of the styles
+		if fs.Lookup(key) == nil {
+			continue
+		}
+		if slices.Contains(pathOptions, key) && value != "" && !filepath.IsAbs(value) {
+			value = filepath.Join(filepath.Dir(path), value)
+		}
+		err = fs.Set(key, value)
+		if err != nil {
+			return fmt.Errorf("%s: invalid value %q for %s: %w", path, value, key, err)
+		}
+		sources[key] = path
+	}
+	return nil
+}
+
+// Set the options of the flag set that have an environment variable, and record the variable as their source
+func applyEnvironment(fs *flag.FlagSet, sources map[string]string) error {
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil || slices.Contains(commandLineOnly, f.Name) {
+			return
+		}
+		value, ok := os.LookupEnv(EnvName(f.Name))
+		if !ok {
+			return
+		}
+		if setErr := fs.Set(f.Name, value); setErr != nil {
+			err = fmt.Errorf("invalid value %q for %s: %w", value, EnvName(f.Name), setErr)
+			return
+		}
+		sources[f.Name] = EnvName(f.Name)
+	})
+	return err
+}
+
+// Write the effective value of every option as a TOML configuration file, with a comment showing where each option that isn't at its default value was set
+func printConfig(w io.Writer, fs *flag.FlagSet, sources map[string]string) {
+	fmt.Fprintf(w, "# effective options of %s\n", fs.Name())
+	fs.VisitAll(func(f *flag.Flag) {
+		if slices.Contains(commandLineOnly, f.Name) {
+			return
+		}
+		line := fmt.Sprintf("%s = %s", f.Name, tomlValue(f))
+		if source, ok := sources[f.Name]; ok {
+			line += " # " + source
+		}
+		fmt.Fprintln(w, line)
+	})
+}
+
+// Return the value of the flag as a TOML value: booleans and integers as they are, and everything else as a string
+func tomlValue(f *flag.Flag) string {
+	value := f.Value.String()
+	if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+		return value
+	}
+	// Integer flags are recognized by their default value, since string flags can also hold numbers
+	if _, err := strconv.ParseInt(f.DefValue, 10, 64); err == nil {
+		if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return value
+		}
+	}
+	return strconv.Quote(value)
+}
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+)
+
+// Print the plan of the style, with --explain-only the style doesn't run. Otherwise it runs with its report held back, so the plan is printed with the sizes measured during the run before the report.
+// The plan is printed even if the run fails, with the sizes of the stages that ran, since it shows where the run stopped
+func explainRun(ctx context.Context, s Style, name string, cfg *Config) error {
+	s.Explain(cfg.Explain, cfg)
+	files := "input files"
+	if len(cfg.InputFiles) == 1 {
+		files = "input file"
+	}
+	title := fmt.Sprintf("%s plan for %d %s", s.Name, len(cfg.InputFiles), files)
+	if !cfg.Explain.Measured() {
+		return cfg.Explain.Write(cfg.Stdout, title)
+	}
+
+	stdout := cfg.Stdout
+	var report bytes.Buffer
+	cfg.Stdout = &report
+	err := run(ctx, s, name, cfg)
+	writeErr := cfg.Explain.Write(stdout, title+", with the sizes measured while it ran")
+	if writeErr == nil {
+		_, writeErr = io.Copy(stdout, &report)
+	}
+	if err != nil {
+		return err
+	}
+	return writeErr
+}
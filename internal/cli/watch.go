@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/failure"
+	"github.com/R0Xps/exercises-in-style-go/internal/terminal"
+	"github.com/R0Xps/exercises-in-style-go/internal/watch"
+)
+
+// Run the style, then run it again every time its input files or its stop words file change, until ctx is canceled by a signal, which is how a watch ends.
+// Every run prints its ranking along with how the words moved since the last run that succeeded. A run that fails prints its error to stderr, and the files are watched again, since they are usually fixed by the next change.
+// On a terminal the screen is cleared before every ranking, so the ranking is redrawn in place
+func watchRuns(ctx context.Context, s Style, name string, cfg *Config) error {
+	paths := append([]string{cfg.StopWordsFile}, cfg.InputFiles...)
+	snapshot := watch.Take(paths)
+	_, isTerminal := terminal.Width(cfg.Stdout)
+
+	var previous []watch.Entry
+	status := fmt.Sprintf("watching %d files", len(paths))
+	for {
+		entries, err := rank(ctx, s, name, cfg)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if isTerminal {
+			fmt.Fprint(cfg.Stdout, "\x1b[H\x1b[2J")
+		} else if previous != nil {
+			fmt.Fprintln(cfg.Stdout)
+		}
+		fmt.Fprintf(cfg.Stdout, "%s at %s, %s\n\n", name, time.Now().Format(time.TimeOnly), status)
+		if err != nil {
+			failure.Print(os.Stderr, name, err, "")
+		} else {
+			moves, dropped := watch.Diff(previous, entries)
+			err = watch.Write(cfg.Stdout, moves, dropped)
+			if err != nil {
+				return err
+			}
+			previous = entries
+		}
+
+		var changed []string
+		snapshot, changed, err = cfg.Watch.Wait(ctx, snapshot)
+		if err != nil {
+			return nil
+		}
+		for i, path := range changed {
+			changed[i] = filepath.Base(path)
+		}
+		status = strings.Join(changed, ", ") + " changed"
+	}
+}
+
+// Run the style once with a copy of the config, and return the ranking it printed
+func rank(ctx context.Context, s Style, name string, cfg *Config) ([]watch.Entry, error) {
+	c := cfg.Clone()
+	// The ranking is read from the json format, and printed again along with how it moved
+	c.Output.Format = "json"
+	c.Output.Partial = false
+	var out bytes.Buffer
+	c.Stdout = &out
+	c.Progress.Restart(name)
+	err := run(ctx, s, name, c)
+	if err != nil {
+		return nil, err
+	}
+
+	var report struct {
+		Entries []watch.Entry `json:"entries"`
+	}
+	err = json.Unmarshal(out.Bytes(), &report)
+	if err != nil {
+		return nil, fmt.Errorf("reading the report: %w", err)
+	}
+	return report.Entries, nil
+}
@@ -0,0 +1,359 @@
+// Package compare runs several styles in-process on the same inputs, checks that they agree on the ranking, and measures how long each of them takes and how much memory it uses
+package compare
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/metrics"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/cli"
+	"github.com/R0Xps/exercises-in-style-go/internal/failure"
+	"github.com/R0Xps/exercises-in-style-go/internal/styles"
+)
+
+// Command is the compare command, it takes the same options and arguments as the styles, and runs the styles chosen with --styles
+var Command = cli.Style{Name: "compare", Run: run, Flags: registerFlags}
+
+// ErrDisagree is returned with the failure.Disagreement kind after printing the comparison when the styles don't agree on the ranking, so the command fails like diff does
+var ErrDisagree = errors.New("the styles don't agree on the ranking")
+
+// The options that only compare has
+type options struct {
+	// The styles chosen with --styles, in the order they are run. Every style is run if it's empty
+	styles []cli.Style
+}
+
+// Register the --styles option, and return the options it sets
+func registerFlags(fs *flag.FlagSet) any {
+	opts := &options{}
+	fs.Func("styles", "compare only the comma-separated `list` of styles (default all of them)", func(value string) error {
+		opts.styles = nil
+		for _, name := range strings.Split(value, ",") {
+			s, ok := styles.Get(strings.TrimSpace(name))
+			if !ok {
+				return fmt.Errorf("unknown style %q, run 'eis styles' to list the styles", name)
+			}
+			opts.styles = append(opts.styles, s)
+		}
+		return nil
+	})
+	return opts
+}
+
+// Entry is a ranked word, as it is read from the json report of a style
+type Entry struct {
+	Word  string `json:"word"`
+	Count int    `json:"count"`
+}
+
+// Measurement is the ranking a style printed, and the resources it used to compute it
+type Measurement struct {
+	Style string
+	// Wall is the time the style took to run, including printing its report
+	Wall time.Duration
+	// Allocs and AllocBytes are the number of heap allocations the style made, and their total size
+	Allocs     uint64
+	AllocBytes uint64
+	// PeakHeap is the highest size the heap reached while the style ran, above its size before the style started
+	PeakHeap uint64
+	// Entries is the ranking the style printed
+	Entries []Entry
+}
+
+// Difference is a rank at which a style's ranking differs from the reference ranking, which is the ranking of the first style.
+// Expected or Got is nil if one of the rankings is shorter than the other
+type Difference struct {
+	Style     string `json:"style"`
+	Reference string `json:"reference"`
+	Rank      int    `json:"rank"`
+	Expected  *Entry `json:"expected"`
+	Got       *Entry `json:"got"`
+}
+
+func run(ctx context.Context, cfg *cli.Config) error {
+	// The styles' rankings are compared as json, so compare can write either a table or a json document itself
+	if cfg.Output.Format != "plain" && cfg.Output.Format != "json" {
+		return failure.Wrap(failure.Usage, fmt.Errorf("compare can only write the plain and json formats, not %q", cfg.Output.Format))
+	}
+
+	var list []cli.Style
+	if opts, ok := cfg.Options.(*options); ok {
+		list = opts.styles
+	}
+	if len(list) == 0 {
+		list = styles.All
+	}
+
+	measurements := make([]Measurement, 0, len(list))
+	for _, s := range list {
+		m, err := Measure(ctx, s, cfg)
+		if err != nil {
+			return fmt.Errorf("%s: %w", s.Name, err)
+		}
+		measurements = append(measurements, m)
+	}
+
+	differences := Diff(measurements)
+	var err error
+	if cfg.Output.Format == "json" {
+		err = writeJSON(cfg.Stdout, measurements, differences)
+	} else {
+		err = writeTable(cfg.Stdout, measurements, differences, cfg.Output.Top)
+	}
+	if err != nil {
+		return err
+	}
+	if len(differences) > 0 {
+		return failure.Wrap(failure.Disagreement, ErrDisagree)
+	}
+	return nil
+}
+
+// Run the style with a copy of the config until ctx is canceled, and return the ranking it printed along with the resources it used.
+// Styles that need a database get a new one in a temporary directory, so they always read the inputs
+func Measure(ctx context.Context, s cli.Style, cfg *cli.Config) (Measurement, error) {
+	m := Measurement{Style: s.Name}
+
+	c := cfg.Clone()
+	c.Output.Format = "json"
+	// A partial ranking isn't compared, the run is stopped as a whole
+	c.Output.Partial = false
+	var out bytes.Buffer
+	c.Stdout = &out
+	if s.Database {
+		dir, err := os.MkdirTemp("", "eis-compare-")
+		if err != nil {
+			return m, failure.Wrap(failure.Storage, err)
+		}
+		defer func() {
+			_ = os.RemoveAll(dir)
+		}()
+		c.DatabaseFile = filepath.Join(dir, s.Name+".db")
+	}
+
+	// The progress is shared by the styles, it shows the one running
+	c.Progress.Restart(s.Name)
+
+	// What the measurement itself allocates is measured around a run that does nothing, and taken out of the style's numbers
+	overhead, _ := measureWindow(func() error { return nil })
+	w, err := measureWindow(func() error { return s.Run(ctx, c) })
+	m.Wall = w.wall
+	if err != nil {
+		return m, err
+	}
+	m.Allocs = w.allocs - min(w.allocs, overhead.allocs)
+	m.AllocBytes = w.allocBytes - min(w.allocBytes, overhead.allocBytes)
+	m.PeakHeap = w.peakHeap
+
+	var report struct {
+		Entries []Entry `json:"entries"`
+	}
+	err = json.Unmarshal(out.Bytes(), &report)
+	if err != nil {
+		return m, fmt.Errorf("reading the report: %w", err)
+	}
+	m.Entries = report.Entries
+	return m, nil
+}
+
+// The resources used by a function run by measureWindow
+type window struct {
+	wall       time.Duration
+	allocs     uint64
+	allocBytes uint64
+	peakHeap   uint64
+}
+
+// Run f and return the time it took, the heap allocations made while it ran, and the highest size the heap reached above its size before f started.
+// The allocations are read with runtime.ReadMemStats, which flushes the counts cached by every P, so even the few allocations of a short run are counted
+func measureWindow(f func() error) (window, error) {
+	// The sampler is set up before the heap is measured, so its own allocations aren't counted
+	sampler := newHeapSampler()
+
+	// The garbage left by the styles that ran before is collected first, so it isn't counted in this style's heap
+	runtime.GC()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	sampler.start()
+
+	start := time.Now()
+	err := f()
+	wall := time.Since(start)
+
+	peakHeap := sampler.stop()
+	runtime.ReadMemStats(&after)
+
+	w := window{
+		wall:       wall,
+		allocs:     after.Mallocs - before.Mallocs,
+		allocBytes: after.TotalAlloc - before.TotalAlloc,
+	}
+	// The heap can grow and shrink between two samples, but the heap at the end of the run was reached too
+	peakHeap = max(peakHeap, after.HeapAlloc)
+	if peakHeap > before.HeapAlloc {
+		w.peakHeap = peakHeap - before.HeapAlloc
+	}
+	return w, err
+}
+
+// heapSampler samples the size of the heap every millisecond in a goroutine of its own, without allocating once it's started
+type heapSampler struct {
+	samples []metrics.Sample
+	ticker  *time.Ticker
+	started chan struct{}
+	stopped chan struct{}
+	peak    chan uint64
+}
+
+// Create and return a pointer to a heapSampler, whose goroutine waits for start to sample the heap
+func newHeapSampler() *heapSampler {
+	h := &heapSampler{
+		samples: []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}},
+		ticker:  time.NewTicker(time.Millisecond),
+		started: make(chan struct{}),
+		stopped: make(chan struct{}),
+		peak:    make(chan uint64),
+	}
+	go h.watch()
+	return h
+}
+
+// Start sampling the heap
+func (h *heapSampler) start() {
+	close(h.started)
+}
+
+// Stop sampling the heap, and return the highest size seen
+func (h *heapSampler) stop() uint64 {
+	close(h.stopped)
+	h.ticker.Stop()
+	return <-h.peak
+}
+
+// Return the current size of the heap. Unlike runtime.ReadMemStats, this doesn't stop the world, so it can be called while the style runs
+func (h *heapSampler) sample() uint64 {
+	metrics.Read(h.samples)
+	return h.samples[0].Value.Uint64()
+}
+
+// Sample the heap on every tick between start and stop, then send the highest size seen to peak
+func (h *heapSampler) watch() {
+	<-h.started
+	highest := uint64(0)
+	for {
+		highest = max(highest, h.sample())
+		select {
+		case <-h.stopped:
+			h.peak <- highest
+			return
+		case <-h.ticker.C:
+		}
+	}
+}
+
+// Return the ranks at which the ranking of every style differs from the ranking of the first one
+func Diff(measurements []Measurement) []Difference {
+	differences := make([]Difference, 0)
+	if len(measurements) == 0 {
+		return differences
+	}
+
+	reference := measurements[0]
+	for _, m := range measurements[1:] {
+		for i := range max(len(reference.Entries), len(m.Entries)) {
+			d := Difference{Style: m.Style, Reference: reference.Style, Rank: i + 1}
+			if i < len(reference.Entries) {
+				d.Expected = &reference.Entries[i]
+			}
+			if i < len(m.Entries) {
+				d.Got = &m.Entries[i]
+			}
+			if d.Expected == nil || d.Got == nil || *d.Expected != *d.Got {
+				differences = append(differences, d)
+			}
+		}
+	}
+	return differences
+}
+
+// Write the differences between the rankings, then a table of the resources used by every style
+func writeTable(w io.Writer, measurements []Measurement, differences []Difference, top int) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "rankings of the top %d words:\n", top)
+	if len(differences) == 0 {
+		fmt.Fprintf(&b, "  all %d styles agree\n", len(measurements))
+	}
+	for _, d := range differences {
+		fmt.Fprintf(&b, "  %s differs from %s at rank %d: expected %s, got %s\n", d.Style, d.Reference, d.Rank, formatEntry(d.Expected), formatEntry(d.Got))
+	}
+	b.WriteString("\n")
+
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "style\twall time\tallocs\tallocated\tpeak heap\t")
+	for _, m := range measurements {
+		fmt.Fprintf(tw, "%s\t%v\t%s\t%s\t%s\t\n", m.Style, m.Wall.Round(10*time.Microsecond), humanize.Comma(int64(m.Allocs)), humanize.IBytes(m.AllocBytes), humanize.IBytes(m.PeakHeap))
+	}
+	err := tw.Flush()
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// Return the entry as it is written in the differences, or "nothing" if the ranking ended before it
+func formatEntry(e *Entry) string {
+	if e == nil {
+		return "nothing"
+	}
+	return fmt.Sprintf("%s - %d", e.Word, e.Count)
+}
+
+// jsonMeasurement is the resources used by a style in the json document
+type jsonMeasurement struct {
+	Style         string  `json:"style"`
+	WallMs        float64 `json:"wall_ms"`
+	Allocs        uint64  `json:"allocs"`
+	AllocBytes    uint64  `json:"alloc_bytes"`
+	PeakHeapBytes uint64  `json:"peak_heap_bytes"`
+}
+
+// Write the measurements and the differences as an indented json document
+func writeJSON(w io.Writer, measurements []Measurement, differences []Difference) error {
+	doc := struct {
+		Agree       bool              `json:"agree"`
+		Differences []Difference      `json:"differences"`
+		Styles      []jsonMeasurement `json:"styles"`
+	}{
+		Agree:       len(differences) == 0,
+		Differences: differences,
+		Styles:      make([]jsonMeasurement, len(measurements)),
+	}
+	for i, m := range measurements {
+		doc.Styles[i] = jsonMeasurement{
+			Style:         m.Style,
+			WallMs:        float64(m.Wall.Microseconds()) / 1000,
+			Allocs:        m.Allocs,
+			AllocBytes:    m.AllocBytes,
+			PeakHeapBytes: m.PeakHeap,
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
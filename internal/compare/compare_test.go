@@ -0,0 +1,121 @@
+package compare
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/cli"
+	"github.com/R0Xps/exercises-in-style-go/internal/failure"
+	"github.com/R0Xps/exercises-in-style-go/internal/report"
+	"github.com/R0Xps/exercises-in-style-go/internal/styles"
+	"github.com/R0Xps/exercises-in-style-go/internal/tokens"
+)
+
+func TestDiff(t *testing.T) {
+	measurements := []Measurement{
+		{Style: "a", Entries: []Entry{{"mr", 3}, {"darcy", 2}, {"lizzy", 1}}},
+		{Style: "b", Entries: []Entry{{"mr", 3}, {"darcy", 2}, {"lizzy", 1}}},
+		{Style: "c", Entries: []Entry{{"mr", 3}, {"darcy", 1}}},
+	}
+	differences := Diff(measurements)
+	if len(differences) != 2 {
+		t.Fatalf("got %d differences, want 2: %v", len(differences), differences)
+	}
+	d := differences[0]
+	if d.Style != "c" || d.Reference != "a" || d.Rank != 2 || *d.Expected != (Entry{"darcy", 2}) || *d.Got != (Entry{"darcy", 1}) {
+		t.Errorf("unexpected difference %+v", d)
+	}
+	// The ranking of c ends before the third rank
+	if d := differences[1]; d.Rank != 3 || d.Got != nil {
+		t.Errorf("unexpected difference %+v", d)
+	}
+}
+
+func TestRun(t *testing.T) {
+	filter := tokens.NewFilter()
+	var out bytes.Buffer
+	cfg := &cli.Config{
+		Filter:        filter,
+		Output:        report.NewOptions(filter),
+		StopWordsFile: filepath.Join("..", "..", "examples", "stop_words.txt"),
+		InputFiles:    []string{filepath.Join("..", "..", "examples", "input", "input1.txt")},
+		Stdout:        &out,
+	}
+
+	opts := &options{styles: []cli.Style{styles.All[0]}}
+	for _, s := range styles.All {
+		if s.Database {
+			opts.styles = append(opts.styles, s)
+		}
+	}
+	cfg.Options = opts
+
+	err := run(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "all 2 styles agree") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+	// The runs don't change the config they are given
+	if cfg.Output.Format != "plain" || filter.Stats().Tokens != 0 {
+		t.Error("the config was changed by the runs")
+	}
+
+	// A style whose ranking differs makes the command fail with its own kind, after printing the comparison
+	empty := cli.Style{Name: "empty", Run: func(_ context.Context, cfg *cli.Config) error {
+		_, err := io.WriteString(cfg.Stdout, `{"entries": []}`)
+		return err
+	}}
+	cfg.Options = &options{styles: []cli.Style{styles.All[0], empty}}
+	out.Reset()
+	err = run(context.Background(), cfg)
+	if !errors.Is(err, ErrDisagree) || failure.KindOf(err) != failure.Disagreement {
+		t.Errorf("got the error %v, want %v with the disagreement kind", err, ErrDisagree)
+	}
+	if !strings.Contains(out.String(), "empty differs from actors at rank 1") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+
+	// Formats other than plain and json can't be used
+	cfg.Output.Format = "csv"
+	if err := run(context.Background(), cfg); err == nil {
+		t.Error("expected an error for the csv format")
+	}
+}
+
+func TestMeasureWindow(t *testing.T) {
+	// Allocations made by a short run are counted, even if they don't fill a P's cache
+	var kept [][]byte
+	w, err := measureWindow(func() error {
+		for range 100 {
+			kept = append(kept, make([]byte, 1<<10))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kept) != 100 || w.allocs < 100 || w.allocBytes < 100<<10 || w.peakHeap < 100<<10 {
+		t.Errorf("100 allocations of 1KiB were measured as %+v", w)
+	}
+
+	overhead, _ := measureWindow(func() error { return nil })
+	if overhead.allocs > 10 || overhead.peakHeap > 16<<10 {
+		t.Errorf("a run that does nothing was measured as %+v", overhead)
+	}
+}
+
+func TestHeapSamplerDoesNotAllocate(t *testing.T) {
+	h := newHeapSampler()
+	h.start()
+	defer h.stop()
+	if allocs := testing.AllocsPerRun(100, func() { h.sample() }); allocs != 0 {
+		t.Errorf("sampling the heap made %v allocations", allocs)
+	}
+}
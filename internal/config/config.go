@@ -0,0 +1,285 @@
+// Package config reads the configuration files that hold default values for the command-line options
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// The names of the configuration files looked for in the current directory, in order
+var localNames = []string{".eis.toml", ".eis.json"}
+
+// The names of the configuration files looked for in the "eis" directory of the user configuration directory, in order
+var userNames = []string{"config.toml", "config.json"}
+
+// Settings maps option names (the same as the flag names, without dashes in front) to their values, written the same way they would be on the command line
+type Settings map[string]string
+
+// Return the path of the configuration file to use when none is given explicitly: the first one found in the current directory, then in the user configuration directory.
+// An empty path is returned if there is none
+func Find() (string, error) {
+	candidates := make([]string, 0)
+	candidates = append(candidates, localNames...)
+	if dir, err := os.UserConfigDir(); err == nil {
+		for _, name := range userNames {
+			candidates = append(candidates, filepath.Join(dir, "eis", name))
+		}
+	}
+
+	for _, path := range candidates {
+		_, err := os.Stat(path)
+		if err == nil {
+			return path, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+// Read the configuration file at the given path, files ending with .json are read as JSON and all others as TOML
+func Load(path string) (Settings, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+
+	var settings Settings
+	if strings.HasSuffix(path, ".json") {
+		settings, err = parseJSON(data)
+	} else {
+		settings, err = parseTOML(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return settings, nil
+}
+
+// Parse a JSON object whose values are strings, numbers, booleans, or arrays of them
+func parseJSON(data []byte) (Settings, error) {
+	var values map[string]any
+	err := json.Unmarshal(data, &values)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := make(Settings)
+	for key, value := range values {
+		s, err := jsonString(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		settings[key] = s
+	}
+	return settings, nil
+}
+
+// Return a JSON value written the way it would be on the command line, arrays become comma-separated lists
+func jsonString(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			s, err := jsonString(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = s
+		}
+		return strings.Join(items, ","), nil
+	}
+	return "", fmt.Errorf("unsupported value %v", value)
+}
+
+// Parse the subset of TOML used by configuration files: "key = value" lines, where the value is a string, an integer, a boolean, or an array of them.
+// Comments start with # and blank lines are ignored. Tables aren't supported, since every option is at the top level
+func parseTOML(data []byte) (Settings, error) {
+	settings := make(Settings)
+	for i, line := range strings.Split(string(data), "\n") {
+		p := &tomlParser{line: line}
+		p.skipSpace()
+		if p.done() {
+			continue
+		}
+		if p.peek() == '[' {
+			return nil, fmt.Errorf("line %d: tables are not supported", i+1)
+		}
+
+		key, err := p.key()
+		if err == nil {
+			p.skipSpace()
+			if !p.consume('=') {
+				err = errors.New("expected '=' after the key")
+			}
+		}
+		var value string
+		if err == nil {
+			p.skipSpace()
+			value, err = p.value()
+		}
+		if err == nil {
+			p.skipSpace()
+			if !p.done() {
+				err = fmt.Errorf("unexpected %q after the value", p.line[p.pos:])
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		if _, ok := settings[key]; ok {
+			return nil, fmt.Errorf("line %d: %s is set more than once", i+1, key)
+		}
+		settings[key] = value
+	}
+	return settings, nil
+}
+
+// tomlParser reads a single line of a TOML file
+type tomlParser struct {
+	line string
+	pos  int
+}
+
+// Check if the rest of the line is empty or a comment
+func (p *tomlParser) done() bool {
+	return p.pos >= len(p.line) || p.line[p.pos] == '#'
+}
+
+// Return the next character of the line, or 0 at the end of the line
+func (p *tomlParser) peek() byte {
+	if p.pos >= len(p.line) {
+		return 0
+	}
+	return p.line[p.pos]
+}
+
+// Skip the next character if it is c, and return whether it was
+func (p *tomlParser) consume(c byte) bool {
+	if p.peek() != c {
+		return false
+	}
+	p.pos++
+	return true
+}
+
+// Skip spaces, tabs, and the carriage return of files with Windows line endings
+func (p *tomlParser) skipSpace() {
+	for p.pos < len(p.line) && strings.IndexByte(" \t\r", p.line[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// Read a bare key (letters, digits, '-' and '_') or a quoted key
+func (p *tomlParser) key() (string, error) {
+	if c := p.peek(); c == '"' || c == '\'' {
+		return p.string()
+	}
+	start := p.pos
+	for p.pos < len(p.line) && isBareKeyChar(p.line[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", errors.New("expected a key")
+	}
+	return p.line[start:p.pos], nil
+}
+
+// Check if the given character can be part of a bare key
+func isBareKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_'
+}
+
+// Read a value and return it the way it would be written on the command line, arrays become comma-separated lists
+func (p *tomlParser) value() (string, error) {
+	switch c := p.peek(); {
+	case c == '"' || c == '\'':
+		return p.string()
+	case c == '[':
+		return p.array()
+	case c == 0 || c == '#':
+		return "", errors.New("expected a value")
+	}
+
+	// Integers and booleans end at the first space, comma, closing bracket or comment
+	start := p.pos
+	for p.pos < len(p.line) && strings.IndexByte(" \t\r,]#", p.line[p.pos]) < 0 {
+		p.pos++
+	}
+	value := p.line[start:p.pos]
+	if value == "true" || value == "false" {
+		return value, nil
+	}
+	n, err := strconv.ParseInt(strings.ReplaceAll(value, "_", ""), 0, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid value %q", value)
+	}
+	return strconv.FormatInt(n, 10), nil
+}
+
+// Read an array of values on a single line
+func (p *tomlParser) array() (string, error) {
+	p.consume('[')
+	items := make([]string, 0)
+	for {
+		p.skipSpace()
+		if p.consume(']') {
+			return strings.Join(items, ","), nil
+		}
+		item, err := p.value()
+		if err != nil {
+			return "", err
+		}
+		items = append(items, item)
+		p.skipSpace()
+		if !p.consume(',') && p.peek() != ']' {
+			return "", errors.New("expected ',' or ']' in the array")
+		}
+	}
+}
+
+// Read a basic string ("..." with backslash escapes) or a literal string ('...' without escapes)
+func (p *tomlParser) string() (string, error) {
+	quote := p.line[p.pos]
+	p.pos++
+
+	var b strings.Builder
+	for p.pos < len(p.line) {
+		c := p.line[p.pos]
+		p.pos++
+		switch {
+		case c == quote:
+			return b.String(), nil
+		case c == '\\' && quote == '"':
+			if p.pos >= len(p.line) {
+				return "", errors.New("unterminated string")
+			}
+			escaped, ok := tomlEscapes[p.line[p.pos]]
+			if !ok {
+				return "", fmt.Errorf("unsupported escape sequence \\%c", p.line[p.pos])
+			}
+			b.WriteByte(escaped)
+			p.pos++
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", errors.New("unterminated string")
+}
+
+// The escape sequences supported in basic strings, by the character after the backslash
+var tomlEscapes = map[byte]byte{'"': '"', '\\': '\\', 'n': '\n', 't': '\t', 'r': '\r'}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseTOML(t *testing.T) {
+	data := `# defaults for the examples
+stop-words = "examples/stop_words.txt"
+top = 1_0 # ten words
+numbers = true
+columns = ["rank", 'word', "count"]
+"token-pattern" = 'C\+\+'
+aliases = "a \"quoted\"\tname"
+`
+	want := Settings{
+		"stop-words":    "examples/stop_words.txt",
+		"top":           "10",
+		"numbers":       "true",
+		"columns":       "rank,word,count",
+		"token-pattern": `C\+\+`,
+		"aliases":       "a \"quoted\"\tname",
+	}
+	got, err := parseTOML([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestParseTOMLErrors(t *testing.T) {
+	for _, data := range []string{
+		"[options]",
+		"top",
+		"top = ",
+		"top = ten",
+		"top = 10 20",
+		`format = "csv`,
+		`format = "\x"`,
+		"columns = [rank word]",
+		"top = 10\ntop = 20",
+	} {
+		if _, err := parseTOML([]byte(data)); err == nil {
+			t.Errorf("%q: expected an error", data)
+		}
+	}
+}
+
+func TestLoadJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(path, []byte(`{"top": 10, "case": false, "columns": ["rank", "word"], "format": "csv"}`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Settings{"top": "10", "case": "false", "columns": "rank,word", "format": "csv"}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
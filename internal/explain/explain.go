@@ -0,0 +1,152 @@
+// Package explain describes the plan a style runs: its stages in order, what each of them does in the terms of the style, and the size of what each of them produced when the style ran
+package explain
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/dustin/go-humanize"
+	"github.com/dustin/go-humanize/english"
+)
+
+// Plan holds the stages of a style, which the style declares before it runs, and the sizes it adds to them as it runs. Every method can be called on a nil Plan, which does nothing, so the styles don't check whether their plan is explained
+type Plan struct {
+	// Enabled prints the plan with the sizes measured while the style runs before its report, it is set by --explain
+	Enabled bool
+	// Only prints the plan instead of running the style, so there are no sizes, it is set by --explain-only
+	Only bool
+
+	mu     sync.Mutex
+	stages []*stage
+	notes  []string
+}
+
+// stage is a step of a plan, with the lines that describe it and the sizes of what it produced, by unit, in the order they were first added
+type stage struct {
+	name   string
+	detail []string
+	units  []string
+	sizes  map[string]int64
+}
+
+// Create and return a pointer to a new Plan that isn't explained
+func New() *Plan {
+	return &Plan{}
+}
+
+// Register the command-line flags that choose whether the plan is explained on the given FlagSet
+func (p *Plan) RegisterFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&p.Enabled, "explain", p.Enabled, "print the stages the style runs, with the size of what each of them produced, before the report")
+	fs.BoolVar(&p.Only, "explain-only", p.Only, "print the stages the style would run without running it")
+}
+
+// Check if the plan is explained, with or without running the style
+func (p *Plan) Explained() bool {
+	return p != nil && (p.Enabled || p.Only)
+}
+
+// Check if the style runs, and the sizes of its stages are measured
+func (p *Plan) Measured() bool {
+	return p.Explained() && !p.Only
+}
+
+// Add a stage to the end of the plan, described by the given lines. A stage that was already added only gets the lines it doesn't have yet
+func (p *Plan) Stage(name string, detail ...string) {
+	if !p.Explained() {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := p.find(name)
+	for _, line := range detail {
+		if !slices.Contains(s.detail, line) {
+			s.detail = append(s.detail, line)
+		}
+	}
+}
+
+// Add n to the size of the given unit at the stage with the given name, like 1200 "word", the unit is made plural when it's printed. The stage is added to the end of the plan if the style didn't declare it
+func (p *Plan) Add(name, unit string, n int64) {
+	if !p.Measured() {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := p.find(name)
+	if _, ok := s.sizes[unit]; !ok {
+		s.units = append(s.units, unit)
+	}
+	s.sizes[unit] += n
+}
+
+// Add a line printed after the stages, about the plan as a whole
+func (p *Plan) Note(format string, args ...any) {
+	if !p.Explained() {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.notes = append(p.notes, fmt.Sprintf(format, args...))
+}
+
+// Return the stage with the given name, which is added if there isn't one. p.mu has to be held
+func (p *Plan) find(name string) *stage {
+	for _, s := range p.stages {
+		if s.name == name {
+			return s
+		}
+	}
+	s := &stage{name: name, sizes: make(map[string]int64)}
+	p.stages = append(p.stages, s)
+	return s
+}
+
+// Write the plan to w under the given title: every stage with its number and the sizes of what it produced, then the lines that describe it
+func (p *Plan) Write(w io.Writer, title string) error {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var b strings.Builder
+	b.WriteString(title + "\n\n")
+	width := 0
+	for _, s := range p.stages {
+		width = max(width, utf8.RuneCountInString(s.name))
+	}
+	for i, s := range p.stages {
+		line := fmt.Sprintf("%2d. %s", i+1, s.name)
+		if sizes := s.format(); sizes != "" {
+			line += strings.Repeat(" ", width-utf8.RuneCountInString(s.name)) + "  -> " + sizes
+		}
+		b.WriteString(line + "\n")
+		for _, detail := range s.detail {
+			b.WriteString("      " + detail + "\n")
+		}
+	}
+	if len(p.notes) > 0 {
+		b.WriteString("\n")
+		for _, note := range p.notes {
+			b.WriteString(note + "\n")
+		}
+	}
+	b.WriteString("\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Return the sizes of the stage separated by commas, like "2 documents, 1,200 words"
+func (s *stage) format() string {
+	sizes := make([]string, len(s.units))
+	for i, unit := range s.units {
+		n := s.sizes[unit]
+		sizes[i] = humanize.Comma(n) + " " + english.PluralWord(int(n), unit, "")
+	}
+	return strings.Join(sizes, ", ")
+}
@@ -0,0 +1,80 @@
+package explain
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPlan(t *testing.T) {
+	p := New()
+	p.Enabled = true
+	p.Stage("read", "readFile(path)")
+	p.Stage("count", "frequencies(words)")
+	// Declaring a stage again only adds the lines it doesn't have yet
+	p.Stage("read", "readFile(path)", "for each input file")
+	p.Add("read", "byte", 1200)
+	p.Add("count", "document", 1)
+	p.Add("count", "distinct word", 40)
+	p.Add("count", "document", 1)
+	p.Add("print", "entry", 1)
+	p.Note("%d documents", 2)
+
+	var out bytes.Buffer
+	if err := p.Write(&out, "test plan"); err != nil {
+		t.Fatal(err)
+	}
+	want := `test plan
+
+ 1. read   -> 1,200 bytes
+      readFile(path)
+      for each input file
+ 2. count  -> 2 documents, 40 distinct words
+      frequencies(words)
+ 3. print  -> 1 entry
+
+2 documents
+
+`
+	if out.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestPlanOnly(t *testing.T) {
+	p := New()
+	p.Only = true
+	if !p.Explained() || p.Measured() {
+		t.Fatalf("a plan printed without running the style is explained but not measured")
+	}
+	p.Stage("read", "readFile(path)")
+	p.Add("read", "byte", 1200)
+
+	var out bytes.Buffer
+	if err := p.Write(&out, "test plan"); err != nil {
+		t.Fatal(err)
+	}
+	if want := "test plan\n\n 1. read\n      readFile(path)\n\n"; out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+}
+
+func TestPlanNotExplained(t *testing.T) {
+	var out bytes.Buffer
+	for _, p := range []*Plan{nil, New()} {
+		p.Stage("read", "readFile(path)")
+		p.Add("read", "byte", 1200)
+		p.Note("note")
+		if p.Explained() || p.Measured() {
+			t.Errorf("the plan %v is explained", p)
+		}
+	}
+	var p *Plan
+	if err := p.Write(&out, "test plan"); err != nil || out.Len() != 0 {
+		t.Errorf("a nil plan wrote %q with the error %v", out.String(), err)
+	}
+	p = New()
+	p.Stage("read")
+	if err := p.Write(&out, "test plan"); err != nil || out.String() != "test plan\n\n\n" {
+		t.Errorf("a plan that isn't explained wrote %q with the error %v", out.String(), err)
+	}
+}
@@ -0,0 +1,163 @@
+// Package failure classifies the errors that stop a program, so that every kind of failure can exit with its own code
+package failure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+// Kind is the category of an error, which decides the exit code of the program
+type Kind int
+
+const (
+	// Internal errors are the ones that don't belong to any other kind, like failing to write the output
+	Internal Kind = iota
+	// Usage errors are invalid options or arguments
+	Usage
+	// Input errors happen while reading the input files, or the other files they are reported with (aliases and templates)
+	Input
+	// StopWords errors happen while reading the stop words file
+	StopWords
+	// Storage errors happen while reading or writing a database
+	Storage
+	// Interrupted is the kind of the error of a run that was stopped by a signal, like Ctrl-C
+	Interrupted
+	// Limit errors happen when a run reaches one of the limits set by the options, like --max-bytes or --timeout
+	Limit
+	// Disagreement is the kind of the error of eis compare when the styles don't agree on the ranking
+	Disagreement
+)
+
+// The names of the kinds, as they are printed in error messages
+var kindNames = map[Kind]string{
+	Internal:     "internal",
+	Usage:        "usage",
+	Input:        "input",
+	StopWords:    "stop-words",
+	Storage:      "storage",
+	Interrupted:  "interrupted",
+	Limit:        "limit",
+	Disagreement: "disagreement",
+}
+
+// Return the name of the kind
+func (k Kind) String() string {
+	return kindNames[k]
+}
+
+// Return the exit code of the program when it fails with an error of this kind, usage errors use 2 like the flag package, and the other kinds follow it.
+// Interrupted runs exit with 130 like shells do for programs killed by SIGINT
+func (k Kind) ExitCode() int {
+	switch k {
+	case Usage:
+		return 2
+	case Input:
+		return 3
+	case StopWords:
+		return 4
+	case Storage:
+		return 5
+	case Limit:
+		return 6
+	case Disagreement:
+		return 7
+	case Interrupted:
+		return 130
+	}
+	return 1
+}
+
+// Error is an error with its kind
+type Error struct {
+	Kind Kind
+	Err  error
+}
+
+// Return the message of the wrapped error
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Return the wrapped error, so errors.Is and errors.As see through the kind
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Return err with the given kind, or nil if err is nil. Errors that already have a kind keep it, so the innermost (most precise) kind wins
+func Wrap(kind Kind, err error) error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return err
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+// Return the kind of the error, errors that weren't given a kind are internal errors.
+// Errors caused by a canceled context are always Interrupted, since the operation that returned them was only stopped because of the cancellation
+func KindOf(err error) Kind {
+	if errors.Is(err, context.Canceled) {
+		return Interrupted
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return Internal
+}
+
+// Write the error as a single logfmt line (key=value pairs separated by spaces), so scripts can parse it:
+//
+//	command=monolithic level=error kind=input exit=3 path=missing.txt msg="open missing.txt: no such file or directory"
+//
+// The path is only written for errors about a file, and hint is only written if it isn't empty
+func Print(w io.Writer, command string, err error, hint string) {
+	kind := KindOf(err)
+	fields := []string{
+		"command=" + logfmtValue(command),
+		"level=error",
+		"kind=" + kind.String(),
+		"exit=" + strconv.Itoa(kind.ExitCode()),
+	}
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		fields = append(fields, "path="+logfmtValue(pathErr.Path))
+	}
+	fields = append(fields, "msg="+logfmtValue(err.Error()))
+	if hint != "" {
+		fields = append(fields, "hint="+logfmtValue(hint))
+	}
+	fmt.Fprintln(w, strings.Join(fields, " "))
+}
+
+// Write a warning as a single logfmt line, in the same format as the errors written by Print but without a kind or an exit code, since the program goes on:
+//
+//	command=persistent_tables level=warning msg="b.txt isn't stored in words.db" hint="run 'persistent_tables add' to add it"
+//
+// The hint is only written if it isn't empty
+func Warn(w io.Writer, command string, msg string, hint string) {
+	fields := []string{
+		"command=" + logfmtValue(command),
+		"level=warning",
+		"msg=" + logfmtValue(msg),
+	}
+	if hint != "" {
+		fields = append(fields, "hint="+logfmtValue(hint))
+	}
+	fmt.Fprintln(w, strings.Join(fields, " "))
+}
+
+// Return the value quoted if it's empty or has spaces, quotes, '=' or control characters, otherwise as it is
+func logfmtValue(s string) string {
+	if s == "" || strings.ContainsFunc(s, func(r rune) bool { return r <= ' ' || r == '"' || r == '=' || r == 0x7f }) {
+		return strconv.Quote(s)
+	}
+	return s
+}
@@ -0,0 +1,60 @@
+package failure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestWrap(t *testing.T) {
+	if Wrap(Input, nil) != nil {
+		t.Error("wrapping nil should return nil")
+	}
+	if kind := KindOf(errors.New("plain")); kind != Internal {
+		t.Errorf("got kind %v for an error without a kind", kind)
+	}
+
+	// The innermost kind is kept when an error is wrapped again
+	err := Wrap(Storage, fmt.Errorf("reading: %w", Wrap(StopWords, os.ErrNotExist)))
+	if kind := KindOf(err); kind != StopWords {
+		t.Errorf("got kind %v, want %v", kind, StopWords)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Error("the wrapped error should still be found by errors.Is")
+	}
+
+	// Errors caused by a cancellation are interrupted whatever kind they were given
+	err = Wrap(Storage, fmt.Errorf("inserting data: %w", context.Canceled))
+	if kind := KindOf(err); kind != Interrupted || kind.ExitCode() != 130 {
+		t.Errorf("got kind %v with exit code %d, want %v with exit code 130", kind, kind.ExitCode(), Interrupted)
+	}
+}
+
+func TestPrint(t *testing.T) {
+	_, err := os.Open("missing file.txt")
+	var b strings.Builder
+	Print(&b, "eis monolithic", Wrap(Input, err), "")
+	want := `command="eis monolithic" level=error kind=input exit=3 path="missing file.txt" msg="open missing file.txt: no such file or directory"` + "\n"
+	if b.String() != want {
+		t.Errorf("got %q, want %q", b.String(), want)
+	}
+
+	b.Reset()
+	Print(&b, "things", Wrap(Usage, errors.New("missing input files")), "run 'things --help' for usage")
+	want = `command=things level=error kind=usage exit=2 msg="missing input files" hint="run 'things --help' for usage"` + "\n"
+	if b.String() != want {
+		t.Errorf("got %q, want %q", b.String(), want)
+	}
+}
+
+func TestWarn(t *testing.T) {
+	var b strings.Builder
+	Warn(&b, "persistent_tables", "b.txt isn't stored in words.db", "")
+	want := `command=persistent_tables level=warning msg="b.txt isn't stored in words.db"` + "\n"
+	if b.String() != want {
+		t.Errorf("got %q, want %q", b.String(), want)
+	}
+}
@@ -0,0 +1,127 @@
+// Package follow reads files as they grow, like tail -f, so a run can keep counting the lines appended to its input files
+package follow
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/limits"
+)
+
+// Options configure how the input files are followed
+type Options struct {
+	// Enabled makes the run keep reading its input files as they grow, it is set by --follow
+	Enabled bool
+	// Interval is the time between two reads of the input files, and between two rankings
+	Interval time.Duration
+}
+
+// Create and return a pointer to new Options that don't follow the input files, and read them every 2 seconds once they do
+func New() *Options {
+	return &Options{Interval: 2 * time.Second}
+}
+
+// Register the command-line flags that configure how the input files are followed on the given FlagSet
+func (o *Options) RegisterFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&o.Enabled, "follow", o.Enabled, "keep reading the lines appended to the input files like tail -f, and print the updated ranking until interrupted")
+	fs.DurationVar(&o.Interval, "follow-interval", o.Interval, "read the appended lines and print the ranking every `duration`")
+}
+
+// Tail reads a file as it grows. It notices when the file is truncated, and when it's rotated, which is when another file takes its path
+type Tail struct {
+	path string
+	file *os.File
+	// offset is how far the file has been read
+	offset int64
+	// pending holds the bytes read after the last line break, since the last line is only returned once it ends
+	pending []byte
+}
+
+// Create and return a pointer to a new Tail of the file at path, which is opened by the first call to Read
+func NewTail(path string) *Tail {
+	return &Tail{path: path}
+}
+
+// Return the complete lines appended to the file since the last call, the first call returns the whole file, with its last line even if it isn't finished, so the file is counted as it is when it's opened.
+// The text appended to that line later is returned once its line ends, so a word split between the first call and the ones after it is returned as two words. The bytes read count towards the limits l.
+// A file that is shorter than what was read was truncated, and is read again from the start. If another file took its path, the rest of the old file is returned along with the lines of the new file.
+// The first call fails if the file doesn't exist, but a file that is removed later is only read again once it's back
+func (t *Tail) Read(l *limits.Limits) ([]byte, error) {
+	opened := t.file == nil
+	if opened {
+		file, err := os.Open(filepath.Clean(t.path))
+		if err != nil {
+			return nil, err
+		}
+		t.file = file
+	}
+
+	data, err := t.readAppended(l)
+	if err != nil {
+		return nil, err
+	}
+	if opened {
+		data = append(data, t.pending...)
+		t.pending = nil
+	}
+
+	current, err := t.file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(t.path)
+	if err != nil || os.SameFile(info, current) {
+		return data, nil
+	}
+
+	// The file was rotated. Nothing is appended to the old file anymore, so its last line is complete
+	if len(t.pending) > 0 {
+		data = append(append(data, t.pending...), '\n')
+	}
+	_ = t.Close()
+	more, err := t.Read(l)
+	return append(data, more...), err
+}
+
+// Read the bytes appended to the open file since the last read, and return the lines they complete
+func (t *Tail) readAppended(l *limits.Limits) ([]byte, error) {
+	info, err := t.file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() < t.offset {
+		t.offset = 0
+		t.pending = nil
+	}
+	if info.Size() == t.offset {
+		return nil, nil
+	}
+
+	appended, err := l.ReadAll(t.path, io.NewSectionReader(t.file, t.offset, info.Size()-t.offset))
+	if err != nil {
+		return nil, err
+	}
+	t.offset += int64(len(appended))
+
+	data := append(t.pending, appended...)
+	end := bytes.LastIndexByte(data, '\n') + 1
+	t.pending = slices.Clone(data[end:])
+	return data[:end], nil
+}
+
+// Close the file, a Tail that is read again after it's closed opens the file at its path and reads it from the start
+func (t *Tail) Close() error {
+	if t.file == nil {
+		return nil
+	}
+	err := t.file.Close()
+	t.file = nil
+	t.offset = 0
+	t.pending = nil
+	return err
+}
@@ -0,0 +1,59 @@
+package follow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	write := func(flag int, data string) {
+		t.Helper()
+		file, err := os.OpenFile(path, flag|os.O_WRONLY|os.O_CREATE, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = file.WriteString(data)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	tail := NewTail(path)
+	defer func() {
+		_ = tail.Close()
+	}()
+	read := func(want string) {
+		t.Helper()
+		data, err := tail.Read(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != want {
+			t.Errorf("got %q, want %q", data, want)
+		}
+	}
+
+	// The first read returns the whole file, with its unfinished last line, and the rest of that line once it ends
+	write(os.O_TRUNC, "error disk full\nwarning cpu")
+	read("error disk full\nwarning cpu")
+	write(os.O_APPEND, " hot\nerror")
+	read(" hot\n")
+	read("")
+
+	// A truncated file is read from the start, and the unfinished line before it is dropped
+	write(os.O_TRUNC, "restarted\n")
+	read("restarted\n")
+
+	// A rotated file is read to its end before the new file is read
+	write(os.O_APPEND, "last line")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	read("")
+	write(os.O_TRUNC, "new file\n")
+	read("last line\nnew file\n")
+}
@@ -0,0 +1,215 @@
+// Package limits bounds the resources a run can use: the size of its input files, the number of distinct words it keeps from all of them, and how long it runs
+package limits
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/failure"
+)
+
+// Policy decides what happens when the input files have more distinct words than the limit
+type Policy string
+
+const (
+	// Fail stops the run with an error
+	Fail Policy = "fail"
+	// Evict forgets the least frequent words counted so far to make room for the new one, so the run goes on with approximate counts
+	Evict Policy = "evict"
+)
+
+// The errors wrapped by the errors of runs that reach a limit, so they can be recognized with errors.Is
+var (
+	ErrMaxBytes       = errors.New("the input files are larger than --max-bytes")
+	ErrMaxUniqueWords = errors.New("more unique words than --max-unique-words")
+	ErrTimeout        = errors.New("the run took longer than --timeout")
+)
+
+// Limits holds the limits of a run. The zero value and a nil pointer have no limits, so styles can use the limits of any config
+type Limits struct {
+	// MaxBytes is the most bytes read from all the input files together, 0 means no limit
+	MaxBytes int64
+	// MaxUniqueWords is the most distinct words kept from all the input files together, 0 means no limit
+	MaxUniqueWords int
+	// Policy is what happens when the input files have more distinct words than MaxUniqueWords
+	Policy Policy
+	// Timeout is the longest a run can take, 0 means no limit
+	Timeout time.Duration
+
+	// read counts the bytes read from the input files so far, and evicted the occurrences of the words evicted so far. They are atomic since some styles read and count their files in other goroutines
+	read    atomic.Int64
+	evicted atomic.Int64
+	// words holds the occurrences of every distinct word kept so far in all the input files, so MaxUniqueWords applies to all of them together. It is guarded by mu for the same reason
+	mu    sync.Mutex
+	words map[string]int
+}
+
+// Create and return a pointer to new Limits without any limit, which fail when a limit is set and reached
+func New() *Limits {
+	return &Limits{Policy: Fail}
+}
+
+// Create and return a pointer to new Limits with the same settings as l, which start counting the bytes read, the words kept and the evicted words from zero
+func (l *Limits) Clone() *Limits {
+	if l == nil {
+		return nil
+	}
+	return &Limits{
+		MaxBytes:       l.MaxBytes,
+		MaxUniqueWords: l.MaxUniqueWords,
+		Policy:         l.Policy,
+		Timeout:        l.Timeout,
+	}
+}
+
+// Register the command-line flags that set the limits on the given FlagSet
+func (l *Limits) RegisterFlags(fs *flag.FlagSet) {
+	fs.Var((*bytesValue)(&l.MaxBytes), "max-bytes", "fail if the input files are larger than `size` together, like 10MB or 64KiB (0 means no limit)")
+	fs.IntVar(&l.MaxUniqueWords, "max-unique-words", l.MaxUniqueWords, "keep at most `n` distinct words from all the input files together (0 means no limit)")
+	fs.Var((*policyValue)(&l.Policy), "unique-words-policy", "what to do when the input files have too many distinct words, `fail` or evict the least frequent word counted so far")
+	l.RegisterTimeoutFlag(fs)
+}
+
+// Register only the --timeout flag on the given FlagSet, for the commands that don't read input files, so the other limits don't apply to them
+func (l *Limits) RegisterTimeoutFlag(fs *flag.FlagSet) {
+	fs.DurationVar(&l.Timeout, "timeout", l.Timeout, "stop the run if it takes longer than `duration`, like 30s or 2m (0 means no limit)")
+}
+
+// Return a copy of ctx that is canceled when the timeout is over, with a cause that says the run reached its timeout, and the function that releases it
+func (l *Limits) WithTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if l == nil || l.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	cause := failure.Wrap(failure.Limit, fmt.Errorf("%w of %v", ErrTimeout, l.Timeout))
+	return context.WithTimeoutCause(ctx, l.Timeout, cause)
+}
+
+// Read r until EOF and return what it holds, r is the input file at path. The bytes read count towards MaxBytes together with the other input files, and reading fails without returning anything once they go over it
+func (l *Limits) ReadAll(path string, r io.Reader) ([]byte, error) {
+	if l == nil || l.MaxBytes <= 0 {
+		return io.ReadAll(r)
+	}
+
+	// One byte more than the limit is read, so a file that reaches the limit exactly isn't taken for a larger one.
+	// The bytes are added to the ones read from the other files at once, and the total it returns is checked, so files read at the same time can't both take what is left
+	data, err := io.ReadAll(io.LimitReader(r, l.MaxBytes+1))
+	read := l.read.Add(int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	if read > l.MaxBytes {
+		return nil, failure.Wrap(failure.Limit, &fs.PathError{Op: "read", Path: path, Err: fmt.Errorf("%w of %s", ErrMaxBytes, humanize.IBytes(uint64(l.MaxBytes)))})
+	}
+	return data, nil
+}
+
+// Count n more occurrences of word, read from the input file at path, in the words kept from all the input files, and return the words evicted to make room for it.
+// If MaxUniqueWords words are already kept and word isn't one of them, the Fail policy returns the error of TooManyWords, and the Evict policy forgets the least frequent word kept so far.
+// The evicted words are forgotten in every input file, so the style removes them from the counts of all its documents, and they start from zero if they come back
+func (l *Limits) Admit(path, word string, n int) ([]string, error) {
+	if l == nil || l.MaxUniqueWords <= 0 {
+		return nil, nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.words == nil {
+		l.words = make(map[string]int)
+	}
+	if _, ok := l.words[word]; ok || len(l.words) < l.MaxUniqueWords {
+		l.words[word] += n
+		return nil, nil
+	}
+	if l.Policy != Evict {
+		return nil, l.TooManyWords(path)
+	}
+	evicted, freq := leastFrequent(l.words)
+	delete(l.words, evicted)
+	l.evicted.Add(int64(freq))
+	l.words[word] = n
+	return []string{evicted}, nil
+}
+
+// Forget the words kept so far and the occurrences evicted, for a style that counts the words it already read again
+func (l *Limits) ResetWords() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.words = nil
+	l.evicted.Store(0)
+}
+
+// Return the number of occurrences of the words evicted so far
+func (l *Limits) Evicted() int {
+	if l == nil {
+		return 0
+	}
+	return int(l.evicted.Load())
+}
+
+// Return the error of a run that stops because the input file at path has a word that doesn't fit in MaxUniqueWords
+func (l *Limits) TooManyWords(path string) error {
+	err := fmt.Errorf("%w of %d, use --unique-words-policy evict to keep counting the most frequent words", ErrMaxUniqueWords, l.MaxUniqueWords)
+	return failure.Wrap(failure.Limit, &fs.PathError{Op: "count", Path: path, Err: err})
+}
+
+// Return the least frequent word of counts along with its frequency. Of the words with the same frequency, the one that sorts last is returned, which is the one the report would rank last,
+// so the word evicted doesn't depend on the order the map is iterated in, and every style evicts the same words
+func leastFrequent(counts map[string]int) (string, int) {
+	var least string
+	lowest := 0
+	for word, freq := range counts {
+		if least == "" || freq < lowest || (freq == lowest && word > least) {
+			least, lowest = word, freq
+		}
+	}
+	return least, lowest
+}
+
+// bytesValue is a flag.Value holding a number of bytes, which can be given with a unit like 10MB or 64KiB
+type bytesValue int64
+
+func (b *bytesValue) String() string {
+	if b == nil {
+		return "0"
+	}
+	return strconv.FormatInt(int64(*b), 10)
+}
+
+func (b *bytesValue) Set(value string) error {
+	n, err := humanize.ParseBytes(value)
+	if err != nil {
+		return fmt.Errorf("invalid size %q", value)
+	}
+	*b = bytesValue(n)
+	return nil
+}
+
+// policyValue is a flag.Value that only accepts the names of the policies
+type policyValue Policy
+
+func (p *policyValue) String() string {
+	if p == nil {
+		return ""
+	}
+	return string(*p)
+}
+
+func (p *policyValue) Set(value string) error {
+	if value != string(Fail) && value != string(Evict) {
+		return fmt.Errorf("unknown policy %q, it has to be fail or evict", value)
+	}
+	*p = policyValue(value)
+	return nil
+}
@@ -0,0 +1,135 @@
+package limits
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/failure"
+)
+
+func TestReadAll(t *testing.T) {
+	l := New()
+	l.MaxBytes = 10
+
+	// The limit is shared by all the input files, so a file that reaches it exactly is read, and the next one fails
+	data, err := l.ReadAll("a.txt", strings.NewReader("0123456789"))
+	if err != nil || string(data) != "0123456789" {
+		t.Fatalf("got %q, %v", data, err)
+	}
+	_, err = l.ReadAll("b.txt", strings.NewReader("x"))
+	if !errors.Is(err, ErrMaxBytes) || failure.KindOf(err) != failure.Limit || !strings.Contains(err.Error(), "b.txt") {
+		t.Errorf("expected a limit error for b.txt, got %v", err)
+	}
+
+	// The copy of the limits counts from zero, and nil limits don't limit anything
+	if _, err := l.Clone().ReadAll("a.txt", strings.NewReader("0123456789")); err != nil {
+		t.Errorf("the clone should count from zero, got %v", err)
+	}
+	var none *Limits
+	if data, err := none.ReadAll("a.txt", strings.NewReader("abc")); err != nil || string(data) != "abc" {
+		t.Errorf("got %q, %v without limits", data, err)
+	}
+}
+
+func TestReadAllConcurrently(t *testing.T) {
+	l := New()
+	l.MaxBytes = 10
+
+	// The files read at the same time can't go over the limit together, so only as many of them as fit are read
+	var wg sync.WaitGroup
+	var read atomic.Int64
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := l.ReadAll("a.txt", strings.NewReader("ab")); err == nil {
+				read.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if read.Load() != 5 {
+		t.Errorf("%d files were read, want 5", read.Load())
+	}
+}
+
+func TestAdmit(t *testing.T) {
+	l := New()
+	l.MaxUniqueWords = 3
+	admit := func(path, word string) ([]string, error) {
+		t.Helper()
+		return l.Admit(path, word, 1)
+	}
+
+	// The limit applies to the words of all the input files together, and words that are already kept always have room
+	for _, word := range []string{"a", "a", "a", "b"} {
+		if _, err := admit("one.txt", word); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, word := range []string{"c", "a", "c"} {
+		if _, err := admit("two.txt", word); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := admit("two.txt", "d")
+	if !errors.Is(err, ErrMaxUniqueWords) || failure.KindOf(err) != failure.Limit || !strings.Contains(err.Error(), "two.txt") {
+		t.Errorf("expected a limit error for two.txt, got %v", err)
+	}
+
+	// Evicting only removes the least frequent word, which is counted once in all the input files
+	l.Policy = Evict
+	evicted, err := admit("two.txt", "d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(evicted, []string{"b"}) || l.Evicted() != 1 {
+		t.Errorf("got %v evicted with %d occurrences, want b with 1", evicted, l.Evicted())
+	}
+	// The occurrences of the evicted words are counted, and a clone starts counting them over
+	if l.Clone().Evicted() != 0 {
+		t.Error("the clone should count the evicted occurrences from zero")
+	}
+	l.ResetWords()
+	if evicted, err := admit("one.txt", "e"); l.Evicted() != 0 || evicted != nil || err != nil {
+		t.Errorf("got %v, %v and %d evicted occurrences after a reset", evicted, err, l.Evicted())
+	}
+
+	// Nil limits and limits without a maximum keep every word
+	var none *Limits
+	if evicted, err := none.Admit("one.txt", "a", 1); evicted != nil || err != nil {
+		t.Errorf("got %v, %v without limits", evicted, err)
+	}
+}
+
+func TestLeastFrequent(t *testing.T) {
+	// When every word has the same frequency, only one of them is evicted, the one that sorts last, whatever the order of the map
+	counts := map[string]int{"b": 1, "d": 1, "a": 1, "c": 1}
+	for range 10 {
+		if word, freq := leastFrequent(counts); word != "d" || freq != 1 {
+			t.Fatalf("got %q with %d, want d with 1", word, freq)
+		}
+	}
+	if word, freq := leastFrequent(map[string]int{"a": 1, "z": 3, "b": 1}); word != "b" || freq != 1 {
+		t.Errorf("got %q with %d, want b with 1", word, freq)
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	l := New()
+	l.Timeout = time.Millisecond
+	ctx, cancel := l.WithTimeout(context.Background())
+	defer cancel()
+
+	<-ctx.Done()
+	cause := context.Cause(ctx)
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) || !errors.Is(cause, ErrTimeout) || failure.KindOf(cause) != failure.Limit {
+		t.Errorf("got error %v with cause %v", ctx.Err(), cause)
+	}
+}
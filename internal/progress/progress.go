@@ -0,0 +1,258 @@
+// Package progress reports how far a long run has got on stderr: the bytes of the input files processed, the tokens counted, the distinct words kept, the throughput and the time left.
+// Every style reports its own checkpoints, like the lines it scans, the stages of its pipeline or the rows it inserts
+package progress
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/terminal"
+)
+
+// Progress holds the counters of a run and prints them regularly while it is started.
+// A nil pointer and a Progress that isn't enabled ignore every checkpoint, so styles can report to the progress of any config
+type Progress struct {
+	// Enabled makes Start print the progress, it is set by --progress
+	Enabled bool
+	// Interval is the time between two progress lines
+	Interval time.Duration
+
+	// The counters are atomic since some styles report their checkpoints from several goroutines
+	doc         atomic.Int64
+	done        atomic.Int64
+	total       atomic.Int64
+	tokens      atomic.Int64
+	uniqueWords atomic.Int64
+	stage       atomic.Pointer[string]
+
+	// The fields below are set by Start, and mu guards them while the progress is printed
+	mu       sync.Mutex
+	w        io.Writer
+	name     string
+	inputs   []string
+	sizes    []int64
+	start    time.Time
+	terminal bool
+	width    int
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// Create and return a pointer to a new Progress that isn't enabled
+func New() *Progress {
+	return &Progress{Interval: time.Second}
+}
+
+// Register the command-line flags that configure the progress on the given FlagSet
+func (p *Progress) RegisterFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&p.Enabled, "progress", p.Enabled, "print the progress of the run on stderr: bytes processed, tokens, unique words, throughput and ETA")
+	fs.DurationVar(&p.Interval, "progress-interval", p.Interval, "print the progress every `duration`")
+}
+
+// Check if the checkpoints are recorded
+func (p *Progress) active() bool {
+	return p != nil && p.Enabled
+}
+
+// Start printing the progress of the command called name on the given input files to w, until Stop is called. It does nothing if the progress isn't enabled.
+// On a terminal the progress is a single line that is redrawn, otherwise a new line is printed every interval so it can be logged
+func (p *Progress) Start(w io.Writer, name string, inputFiles []string) {
+	if !p.active() {
+		return
+	}
+	p.w = w
+	p.name = name
+	p.inputs = inputFiles
+	// The sizes of the files tell how much of the input is left. A file that can't be read has no size, and the style fails on it anyway
+	p.sizes = make([]int64, len(inputFiles))
+	for i, inputFile := range inputFiles {
+		if info, err := os.Stat(inputFile); err == nil {
+			p.sizes[i] = info.Size()
+		}
+	}
+	p.start = time.Now()
+	p.width, p.terminal = terminal.Width(w)
+	stop := make(chan struct{})
+	p.stop = stop
+
+	interval := max(p.Interval, 10*time.Millisecond)
+	p.wg.Go(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				p.print()
+			}
+		}
+	})
+}
+
+// Stop printing the progress, it can be called more than once. On a terminal the progress line is cleared, so it doesn't get in the way of the report
+func (p *Progress) Stop() {
+	if !p.active() {
+		return
+	}
+	p.mu.Lock()
+	stop := p.stop
+	p.stop = nil
+	p.mu.Unlock()
+	if stop == nil {
+		return
+	}
+	close(stop)
+	p.wg.Wait()
+	if p.terminal {
+		fmt.Fprint(p.w, "\r\x1b[K")
+	}
+}
+
+// Start counting again from zero for the command called name, which runs on the same input files. Commands that run several styles one after the other use it to show the progress of the one running
+func (p *Progress) Restart(name string) {
+	if !p.active() {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.name = name
+	p.start = time.Now()
+	p.doc.Store(0)
+	p.done.Store(0)
+	p.total.Store(0)
+	p.tokens.Store(0)
+	p.uniqueWords.Store(0)
+	p.stage.Store(nil)
+}
+
+// Return a writer that writes to w, and stops the progress before its first write so the report isn't printed over the progress line
+func (p *Progress) Writer(w io.Writer) io.Writer {
+	if !p.active() {
+		return w
+	}
+	return &stoppingWriter{w: w, p: p}
+}
+
+// stoppingWriter is the writer returned by Progress.Writer
+type stoppingWriter struct {
+	w    io.Writer
+	p    *Progress
+	once sync.Once
+}
+
+func (s *stoppingWriter) Write(b []byte) (int, error) {
+	s.once.Do(s.p.Stop)
+	return s.w.Write(b)
+}
+
+// Return the writer the stoppingWriter writes to, so the terminal it writes to can be found
+func (s *stoppingWriter) Unwrap() io.Writer {
+	return s.w
+}
+
+// Record that the style is at the given stage of the input file at index doc, and has done done out of total units of its work on it, like lines, words or partitions.
+// A negative doc means the stage works on all the input files at once, and a total of 0 means the style can't tell how much of the work is done yet
+func (p *Progress) Checkpoint(doc int, stage string, done, total int64) {
+	if !p.active() {
+		return
+	}
+	p.doc.Store(int64(doc))
+	p.done.Store(done)
+	p.total.Store(total)
+	if current := p.stage.Load(); current == nil || *current != stage {
+		p.stage.Store(&stage)
+	}
+}
+
+// Add n to the number of tokens processed
+func (p *Progress) AddTokens(n int64) {
+	if !p.active() {
+		return
+	}
+	p.tokens.Add(n)
+}
+
+// Record the number of distinct words the style keeps for the input file it is counting
+func (p *Progress) SetUniqueWords(n int) {
+	if !p.active() {
+		return
+	}
+	p.uniqueWords.Store(int64(n))
+}
+
+// Return the number of bytes of the input files processed so far, and their total size.
+// The files before the current one are processed, and the current one is processed as far as the work done on it
+func (p *Progress) processed() (int64, int64) {
+	doc := int(p.doc.Load())
+	done, total := p.done.Load(), p.total.Load()
+	size := int64(0)
+	for _, s := range p.sizes {
+		size += s
+	}
+
+	fraction := 0.0
+	if total > 0 {
+		fraction = min(float64(done)/float64(total), 1)
+	}
+	if doc < 0 || doc >= len(p.sizes) {
+		return int64(fraction * float64(size)), size
+	}
+	bytes := int64(fraction * float64(p.sizes[doc]))
+	for _, s := range p.sizes[:doc] {
+		bytes += s
+	}
+	return bytes, size
+}
+
+// Print the current progress, as a line of its own or over the previous one on a terminal
+func (p *Progress) print() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	elapsed := time.Since(p.start)
+	bytes, size := p.processed()
+
+	fields := []string{fmt.Sprintf("%s/%s", humanize.IBytes(uint64(bytes)), humanize.IBytes(uint64(size)))}
+	if size > 0 {
+		fields[0] = fmt.Sprintf("%d%% %s", bytes*100/size, fields[0])
+	}
+	fields = append(fields,
+		humanize.Comma(p.tokens.Load())+" tokens",
+		humanize.Comma(p.uniqueWords.Load())+" unique words",
+	)
+	throughput := float64(bytes) / elapsed.Seconds()
+	fields = append(fields, humanize.IBytes(uint64(throughput))+"/s")
+	if bytes > 0 && size > bytes {
+		eta := time.Duration(float64(size-bytes) / throughput * float64(time.Second))
+		fields = append(fields, "ETA "+eta.Round(time.Second).String())
+	}
+
+	line := fmt.Sprintf("%s: %s", p.name, strings.Join(fields, ", "))
+	if stage := p.stage.Load(); stage != nil {
+		doc := int(p.doc.Load())
+		if doc >= 0 && doc < len(p.inputs) {
+			line += fmt.Sprintf(" (%s %s)", *stage, filepath.Base(p.inputs[doc]))
+		} else {
+			line += fmt.Sprintf(" (%s)", *stage)
+		}
+	}
+
+	if p.terminal {
+		// A line wider than the terminal would wrap, and the next one would only be drawn over its end
+		if p.width > 1 && len(line) >= p.width {
+			line = line[:p.width-1]
+		}
+		fmt.Fprint(p.w, "\r\x1b[K"+line)
+	} else {
+		fmt.Fprintln(p.w, line)
+	}
+}
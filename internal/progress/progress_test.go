@@ -0,0 +1,88 @@
+package progress
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer that can be written by the progress goroutine while the test reads it
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestProgress(t *testing.T) {
+	dir := t.TempDir()
+	inputFiles := []string{filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")}
+	for _, inputFile := range inputFiles {
+		if err := os.WriteFile(inputFile, bytes.Repeat([]byte("x"), 1000), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := New()
+	p.Enabled = true
+	p.Interval = 10 * time.Millisecond
+	var out syncBuffer
+	p.Start(&out, "test", inputFiles)
+	// Half of the second file is done, so three quarters of the input are processed
+	p.Checkpoint(1, "counting", 50, 100)
+	p.AddTokens(1234)
+	p.SetUniqueWords(56)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(out.String(), "\n") && time.Now().Before(deadline) {
+		time.Sleep(p.Interval)
+	}
+	p.Stop()
+	p.Stop()
+
+	line, _, _ := strings.Cut(out.String(), "\n")
+	for _, want := range []string{"test: 75% 1.5 KiB/2.0 KiB", "1,234 tokens", "56 unique words", "ETA", "(counting b.txt)"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("expected %q in the progress line %q", want, line)
+		}
+	}
+
+	// Nothing is printed once the progress is stopped
+	printed := out.String()
+	time.Sleep(5 * p.Interval)
+	if out.String() != printed {
+		t.Errorf("the progress was printed after Stop: %q", strings.TrimPrefix(out.String(), printed))
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	var out bytes.Buffer
+	for _, p := range []*Progress{nil, New()} {
+		p.Start(&out, "test", nil)
+		p.Checkpoint(-1, "counting", 1, 2)
+		p.AddTokens(1)
+		p.SetUniqueWords(1)
+		p.Restart("other")
+		p.Stop()
+		if w := p.Writer(&out); w != &out {
+			t.Errorf("expected the writer itself, got %T", w)
+		}
+	}
+	if out.Len() > 0 {
+		t.Errorf("expected nothing printed, got %q", out.String())
+	}
+}
@@ -0,0 +1,251 @@
+package repl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// editor reads lines typed on a terminal in raw mode, where it has to echo the keys itself. It keeps a history of the lines read, which is browsed with the up and down keys, and completes the word before the cursor with the tab key
+type editor struct {
+	in     *bufio.Reader
+	out    io.Writer
+	prompt string
+	// history holds the lines read so far, oldest first
+	history []string
+	// complete returns the candidates for the word that starts at index start of the line, which ends at the cursor
+	complete func(line []rune, start int) []string
+
+	// line is the line being edited, and cursor is the index of the rune the cursor is on
+	line   []rune
+	cursor int
+	// browsing is the index in the history of the line shown while browsing it, or len(history) for the line being typed, which is kept in draft
+	browsing int
+	draft    []rune
+	// tabs counts the tab keys pressed in a row, the candidates are listed on the second one
+	tabs int
+}
+
+// Create and return a pointer to a new editor reading keys from in and echoing them to out, with the given prompt and history
+func newEditor(in io.Reader, out io.Writer, prompt string, history []string) *editor {
+	return &editor{in: bufio.NewReader(in), out: out, prompt: prompt, history: history}
+}
+
+// The control keys the editor handles
+const (
+	keyCtrlA     = 1
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyTab       = 9
+	keyCtrlK     = 11
+	keyCtrlL     = 12
+	keyEnter     = 13
+	keyCtrlU     = 21
+	keyCtrlW     = 23
+	keyEscape    = 27
+	keyBackspace = 127
+)
+
+// Read the next line without its leading and trailing spaces, it is added to the history unless it is empty or the same as the previous one. io.EOF is returned when Ctrl-D is pressed on an empty line, and Ctrl-C discards the line being typed and returns an empty one
+func (e *editor) ReadLine() (string, error) {
+	e.line = e.line[:0]
+	e.cursor = 0
+	e.browsing = len(e.history)
+	e.tabs = 0
+	e.redraw()
+
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		if r != keyTab {
+			e.tabs = 0
+		}
+
+		switch r {
+		case keyEnter, '\n':
+			fmt.Fprint(e.out, "\r\n")
+			line := strings.TrimSpace(string(e.line))
+			if line != "" && (len(e.history) == 0 || e.history[len(e.history)-1] != line) {
+				e.history = append(e.history, line)
+			}
+			return line, nil
+		case keyCtrlC:
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", nil
+		case keyCtrlD:
+			if len(e.line) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+			e.delete(e.cursor)
+		case keyBackspace, '\b':
+			e.delete(e.cursor - 1)
+		case keyCtrlA:
+			e.cursor = 0
+		case keyCtrlE:
+			e.cursor = len(e.line)
+		case keyCtrlK:
+			e.line = e.line[:e.cursor]
+		case keyCtrlU:
+			e.line = slices.Delete(e.line, 0, e.cursor)
+			e.cursor = 0
+		case keyCtrlW:
+			start := e.wordStart()
+			e.line = slices.Delete(e.line, start, e.cursor)
+			e.cursor = start
+		case keyCtrlL:
+			fmt.Fprint(e.out, "\x1b[H\x1b[2J")
+		case keyTab:
+			e.tabs++
+			e.completeWord()
+		case keyEscape:
+			e.escape()
+		default:
+			if unicode.IsPrint(r) {
+				e.line = slices.Insert(e.line, e.cursor, r)
+				e.cursor++
+			}
+		}
+		e.redraw()
+	}
+}
+
+// Handle the escape sequence of a special key, the escape character itself was already read
+func (e *editor) escape() {
+	b, err := e.in.ReadByte()
+	if err != nil || (b != '[' && b != 'O') {
+		return
+	}
+	b, err = e.in.ReadByte()
+	if err != nil {
+		return
+	}
+	switch b {
+	case 'A':
+		e.browse(-1)
+	case 'B':
+		e.browse(1)
+	case 'C':
+		e.cursor = min(e.cursor+1, len(e.line))
+	case 'D':
+		e.cursor = max(e.cursor-1, 0)
+	case 'H':
+		e.cursor = 0
+	case 'F':
+		e.cursor = len(e.line)
+	case '3':
+		// The delete key is "\x1b[3~"
+		if b, err := e.in.ReadByte(); err == nil && b == '~' {
+			e.delete(e.cursor)
+		}
+	}
+}
+
+// Delete the rune at index i of the line, if there is one, and move the cursor back if it was after it
+func (e *editor) delete(i int) {
+	if i < 0 || i >= len(e.line) {
+		return
+	}
+	e.line = slices.Delete(e.line, i, i+1)
+	if e.cursor > i {
+		e.cursor--
+	}
+}
+
+// Show the line step lines away in the history, the line being typed is kept while the history is browsed
+func (e *editor) browse(step int) {
+	next := e.browsing + step
+	if next < 0 || next > len(e.history) {
+		return
+	}
+	if e.browsing == len(e.history) {
+		e.draft = slices.Clone(e.line)
+	}
+	e.browsing = next
+	if next == len(e.history) {
+		e.line = slices.Clone(e.draft)
+	} else {
+		e.line = []rune(e.history[next])
+	}
+	e.cursor = len(e.line)
+}
+
+// Return the index where the word before the cursor starts
+func (e *editor) wordStart() int {
+	start := e.cursor
+	for start > 0 && e.line[start-1] == ' ' {
+		start--
+	}
+	for start > 0 && e.line[start-1] != ' ' {
+		start--
+	}
+	return start
+}
+
+// Complete the word before the cursor with the longest prefix shared by its candidates, or list the candidates below the line if it can't be completed any further and tab was pressed twice
+func (e *editor) completeWord() {
+	if e.complete == nil {
+		return
+	}
+	start := e.cursor
+	for start > 0 && e.line[start-1] != ' ' {
+		start--
+	}
+	candidates := e.complete(e.line[:e.cursor], start)
+	if len(candidates) == 0 {
+		return
+	}
+
+	word := string(e.line[start:e.cursor])
+	completion := commonPrefix(candidates)
+	if len(candidates) == 1 {
+		completion += " "
+	}
+	if len(completion) > len(word) {
+		added := []rune(strings.TrimPrefix(completion, word))
+		e.line = slices.Insert(e.line, e.cursor, added...)
+		e.cursor += len(added)
+		return
+	}
+	if e.tabs >= 2 {
+		fmt.Fprint(e.out, "\r\n"+formatCandidates(candidates)+"\r\n")
+	}
+}
+
+// The most candidates listed below the line
+const maxCandidates = 100
+
+// Return the candidates separated by spaces, and how many were left out if there are too many of them
+func formatCandidates(candidates []string) string {
+	if len(candidates) <= maxCandidates {
+		return strings.Join(candidates, "  ")
+	}
+	return strings.Join(candidates[:maxCandidates], "  ") + fmt.Sprintf("  (%d more)", len(candidates)-maxCandidates)
+}
+
+// Return the longest prefix shared by all the given words
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, word := range words[1:] {
+		for !strings.HasPrefix(word, prefix) {
+			_, size := utf8.DecodeLastRuneInString(prefix)
+			prefix = prefix[:len(prefix)-size]
+		}
+	}
+	return prefix
+}
+
+// Draw the prompt and the line over the current line of the terminal, and put the cursor where it is in the line
+func (e *editor) redraw() {
+	fmt.Fprint(e.out, "\r\x1b[K"+e.prompt+string(e.line))
+	if back := len(e.line) - e.cursor; back > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dD", back)
+	}
+}
@@ -0,0 +1,581 @@
+// Package repl reads the input files once with the managers of the things style, then answers commands typed at a prompt: the count of a word, the words matching a prefix or a regular expression, the pages of the ranking, and the stop words, which can be changed to count the words again
+package repl
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/cli"
+	"github.com/R0Xps/exercises-in-style-go/internal/failure"
+	"github.com/R0Xps/exercises-in-style-go/internal/progress"
+	"github.com/R0Xps/exercises-in-style-go/internal/report"
+	"github.com/R0Xps/exercises-in-style-go/internal/styles/things"
+	"github.com/R0Xps/exercises-in-style-go/internal/terminal"
+	"github.com/R0Xps/exercises-in-style-go/internal/tokens"
+)
+
+// Command is the repl command, it takes the same options and arguments as the styles, and reads its commands from stdin until it ends or quit is typed
+var Command = cli.Style{Name: "repl", Run: run, Flags: registerFlags}
+
+// The options that only repl has
+type options struct {
+	// The file the command history is kept in, set with --history. The history isn't kept if it's empty
+	historyFile string
+}
+
+// The most lines kept in the history file
+const maxHistory = 1000
+
+// Register the --history option, and return the options it sets
+func registerFlags(fs *flag.FlagSet) any {
+	opts := &options{}
+	if dir, err := os.UserCacheDir(); err == nil {
+		opts.historyFile = filepath.Join(dir, "eis", "repl_history")
+	}
+	fs.StringVar(&opts.historyFile, "history", opts.historyFile, "keep the command history in `file`, an empty file keeps no history")
+	return opts
+}
+
+func run(ctx context.Context, cfg *cli.Config) error {
+	wfc, err := things.NewWordFrequencyController(cfg.StopWordsFile, cfg.InputFiles, cfg.Filter, cfg.Limits, cfg.Output, cfg.Stdout)
+	if err != nil {
+		return err
+	}
+	s := newSession(wfc, cfg.Filter, cfg.Output, cfg.Stdout)
+	err = s.recount(ctx, cfg.Progress)
+	// The progress only shows how the files are loaded, it would be drawn over the prompt otherwise
+	cfg.Progress.Stop()
+	if err != nil {
+		return err
+	}
+
+	var historyFile string
+	if opts, ok := cfg.Options.(*options); ok {
+		historyFile = opts.historyFile
+	}
+	history, err := loadHistory(historyFile)
+	if err != nil {
+		return failure.Wrap(failure.Input, fmt.Errorf("reading the history: %w", err))
+	}
+	var reader lineReader
+	if terminal.IsTerminal(os.Stdin) {
+		fmt.Fprintf(cfg.Stdout, "%d words ranked in %d files, type 'help' for the commands\n", len(s.entries), len(cfg.InputFiles))
+		e := newEditor(os.Stdin, cfg.Stdout, "> ", history)
+		e.complete = s.complete
+		reader = &terminalReader{file: os.Stdin, editor: e}
+	} else {
+		reader = &scanReader{scanner: bufio.NewScanner(os.Stdin), history: history}
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	s.history = reader.History
+
+	err = s.loop(ctx, reader)
+	saveErr := saveHistory(historyFile, reader.History())
+	if saveErr != nil {
+		// The session went fine, so it doesn't fail because its history couldn't be kept
+		failure.Print(os.Stderr, "repl", fmt.Errorf("writing the history: %w", saveErr), "")
+	}
+	return err
+}
+
+// lineReader reads the commands of a session one line at a time
+type lineReader interface {
+	// ReadLine returns the next line, or io.EOF once there are no more lines
+	ReadLine() (string, error)
+	// History returns the lines read so far, along with the lines of the history it started with
+	History() []string
+	// Close puts the terminal back as it was, if it was changed
+	Close() error
+}
+
+// terminalReader reads lines typed on a terminal with an editor, the terminal is only in raw mode while a line is typed, so the output of the commands and Ctrl-C work as usual
+type terminalReader struct {
+	file   *os.File
+	editor *editor
+	mu     sync.Mutex
+	// restore puts the terminal back in its previous mode, it is nil when the terminal isn't in raw mode
+	restore func() error
+}
+
+func (r *terminalReader) ReadLine() (string, error) {
+	restore, err := terminal.MakeRaw(r.file)
+	if err != nil {
+		return "", err
+	}
+	r.mu.Lock()
+	r.restore = restore
+	r.mu.Unlock()
+	line, err := r.editor.ReadLine()
+	closeErr := r.Close()
+	if err != nil {
+		return "", err
+	}
+	return line, closeErr
+}
+
+func (r *terminalReader) History() []string {
+	return r.editor.history
+}
+
+func (r *terminalReader) Close() error {
+	r.mu.Lock()
+	restore := r.restore
+	r.restore = nil
+	r.mu.Unlock()
+	if restore == nil {
+		return nil
+	}
+	return restore()
+}
+
+// scanReader reads lines from a file or a pipe, there is no prompt and no line editing
+type scanReader struct {
+	scanner *bufio.Scanner
+	history []string
+}
+
+func (r *scanReader) ReadLine() (string, error) {
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	line := strings.TrimSpace(r.scanner.Text())
+	if line != "" {
+		r.history = append(r.history, line)
+	}
+	return line, nil
+}
+
+func (r *scanReader) History() []string {
+	return r.history
+}
+
+func (r *scanReader) Close() error {
+	return nil
+}
+
+// Return the lines of the history file at path, there is no history if path is empty or the file doesn't exist yet
+func loadHistory(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(filepath.Clean(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return strings.FieldsFunc(string(data), func(r rune) bool {
+		return r == '\n'
+	}), nil
+}
+
+// Write the last maxHistory lines of the history to the file at path, creating its directory if needed. Nothing is written if path is empty
+func saveHistory(path string, history []string) error {
+	if path == "" || len(history) == 0 {
+		return nil
+	}
+	err := os.MkdirAll(filepath.Dir(path), 0o700)
+	if err != nil {
+		return err
+	}
+	history = history[max(len(history)-maxHistory, 0):]
+	return os.WriteFile(path, []byte(strings.Join(history, "\n")+"\n"), 0o600)
+}
+
+// session holds the ranking of the input files and the state of the commands that page through it
+type session struct {
+	wfc    *things.WordFrequencyController
+	filter *tokens.Filter
+	// output holds the report options the ranking is processed with, it keeps every word
+	output *report.Options
+	out    io.Writer
+	// pageSize is the number of words shown by the commands that list words, it is set by --top
+	pageSize int
+
+	// inputs holds the paths of the input files, and entries the ranking of their words, sorted by frequency in descending order
+	inputs  []string
+	entries []report.Entry
+	// ranks maps every word of the ranking to its index in entries, and words holds them sorted alphabetically, for completion
+	ranks map[string]int
+	words []string
+
+	// history returns the lines read so far, it is nil when there is no history
+	history func() []string
+
+	// list holds the indexes in entries of the words listed by the last command that lists words, and offset is the index in list of the first word shown
+	list   []int
+	offset int
+}
+
+// Create and return a pointer to a new session using the given controller, which prints its answers to out. The words are ranked like the report of the styles, and listed --top at a time
+func newSession(wfc *things.WordFrequencyController, filter *tokens.Filter, output *report.Options, out io.Writer) *session {
+	all := output.Clone(filter)
+	all.Top = math.MaxInt
+	return &session{wfc: wfc, filter: filter, output: all, out: out, pageSize: output.Top}
+}
+
+// Count the words of the input files again with the current stop words, and rank them. The words counted in each document are reported to p as they are counted
+func (s *session) recount(ctx context.Context, p *progress.Progress) error {
+	result, err := s.wfc.Count(ctx, p)
+	if err != nil {
+		return err
+	}
+	r, err := report.Process(result, s.output)
+	if err != nil {
+		return err
+	}
+
+	s.inputs = r.Inputs
+	s.entries = r.Entries
+	s.ranks = make(map[string]int, len(s.entries))
+	s.words = make([]string, len(s.entries))
+	for i, e := range s.entries {
+		s.ranks[e.Word] = i
+		s.words[i] = e.Word
+	}
+	slices.Sort(s.words)
+	// The listed words were ranked with the previous stop words
+	s.list = nil
+	s.offset = 0
+	return nil
+}
+
+// Read commands from reader and run them until there are no more lines, quit is typed, or ctx is canceled. The error of a command is printed and the session goes on, only the error reading the lines ends it
+func (s *session) loop(ctx context.Context, reader lineReader) error {
+	for {
+		line, err := readLine(ctx, reader)
+		if errors.Is(err, io.EOF) || ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		quit, err := s.execute(ctx, line)
+		if ctx.Err() != nil || quit {
+			return nil
+		}
+		if err != nil {
+			fmt.Fprintln(s.out, "error:", err)
+		}
+	}
+}
+
+// Return the next line of reader, or the error of ctx if it's canceled first
+func readLine(ctx context.Context, reader lineReader) (string, error) {
+	type result struct {
+		line string
+		err  error
+	}
+	read := make(chan result, 1)
+	go func() {
+		line, err := reader.ReadLine()
+		read <- result{line, err}
+	}()
+	select {
+	case r := <-read:
+		return r.line, r.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// command is a command of the session, run returns the error printed in place of its answer
+type command struct {
+	name  string
+	usage string
+	help  string
+	run   func(s *session, ctx context.Context, args []string) error
+}
+
+// The commands of the session, in the order they are listed by help
+var commands []command
+
+func init() {
+	// The help command lists the commands, so they are set when the package is initialized
+	commands = []command{
+		{"count", "count <word>...", "show the count and the rank of the words", (*session).count},
+		{"prefix", "prefix <prefix>", "list the words that start with the prefix", (*session).prefix},
+		{"match", "match <regexp>", "list the words that match the regular expression", (*session).match},
+		{"top", "top [n]", "list the ranking, n words at a time (default --top)", (*session).top},
+		{"next", "next", "show the next page of the last list", (*session).next},
+		{"prev", "prev", "show the previous page of the last list", (*session).prev},
+		{"page", "page <n>", "show the page n of the last list", (*session).page},
+		{"stop", "stop [add|remove <word>...]", "list the stop words, or change them and count the words again", (*session).stop},
+		{"history", "history", "list the commands typed so far", (*session).printHistory},
+		{"help", "help", "list the commands", (*session).help},
+		{"quit", "quit", "end the session, like exit or Ctrl-D", nil},
+	}
+}
+
+// Run the command on the line, and return whether it ends the session along with its error
+func (s *session) execute(ctx context.Context, line string) (bool, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return false, nil
+	}
+	name, args := strings.ToLower(fields[0]), fields[1:]
+	switch name {
+	case "quit", "exit":
+		return true, nil
+	}
+	for _, c := range commands {
+		if c.name == name && c.run != nil {
+			return false, c.run(s, ctx, args)
+		}
+	}
+	return false, fmt.Errorf("unknown command %q, type 'help' for the commands", name)
+}
+
+// Return the words of the arguments as they are counted, which is normalized like the input files
+func (s *session) normalize(args []string) []string {
+	return strings.Fields(s.filter.Normalize([]byte(strings.Join(args, " "))))
+}
+
+// Print the count and the rank of each word of the arguments, along with its count in each input file when there are several of them
+func (s *session) count(_ context.Context, args []string) error {
+	words := s.normalize(args)
+	if len(words) == 0 {
+		return errors.New("usage: count <word>...")
+	}
+	for _, word := range words {
+		i, ok := s.ranks[word]
+		switch {
+		case ok:
+			e := s.entries[i]
+			fmt.Fprintf(s.out, "%s - %d, rank %d of %d\n", e.Word, e.Freq, i+1, len(s.entries))
+			for d, freq := range e.Documents {
+				fmt.Fprintf(s.out, "  %s - %d\n", s.inputs[d], freq)
+			}
+		case s.wfc.IsStopWord(word):
+			fmt.Fprintf(s.out, "%s is a stop word\n", word)
+		default:
+			fmt.Fprintf(s.out, "%s isn't counted in the input files\n", word)
+		}
+	}
+	return nil
+}
+
+// List the words of the ranking that start with the prefix
+func (s *session) prefix(_ context.Context, args []string) error {
+	words := s.normalize(args)
+	if len(words) != 1 {
+		return errors.New("usage: prefix <prefix>")
+	}
+	s.list = s.matching(func(word string) bool {
+		return strings.HasPrefix(word, words[0])
+	})
+	s.offset = 0
+	s.show()
+	return nil
+}
+
+// List the words of the ranking that match the regular expression, which isn't anchored
+func (s *session) match(_ context.Context, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: match <regexp>")
+	}
+	re, err := regexp.Compile(args[0])
+	if err != nil {
+		return err
+	}
+	s.list = s.matching(re.MatchString)
+	s.offset = 0
+	s.show()
+	return nil
+}
+
+// Return the indexes in entries of the words of the ranking for which keep returns true, in rank order
+func (s *session) matching(keep func(word string) bool) []int {
+	list := make([]int, 0)
+	for i, e := range s.entries {
+		if keep(e.Word) {
+			list = append(list, i)
+		}
+	}
+	return list
+}
+
+// List the whole ranking, with pages of n words if n is given
+func (s *session) top(_ context.Context, args []string) error {
+	if len(args) > 1 {
+		return errors.New("usage: top [n]")
+	}
+	if len(args) == 1 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			return fmt.Errorf("the number of words has to be at least 1, not %q", args[0])
+		}
+		s.pageSize = n
+	}
+	s.list = s.matching(func(string) bool {
+		return true
+	})
+	s.offset = 0
+	s.show()
+	return nil
+}
+
+// Show the page after the one shown last
+func (s *session) next(_ context.Context, _ []string) error {
+	if s.list == nil {
+		return errors.New("there is no list to page through, list words with top, prefix or match first")
+	}
+	if s.offset+s.pageSize >= len(s.list) {
+		return errors.New("this is the last page")
+	}
+	s.offset += s.pageSize
+	s.show()
+	return nil
+}
+
+// Show the page before the one shown last
+func (s *session) prev(_ context.Context, _ []string) error {
+	if s.list == nil {
+		return errors.New("there is no list to page through, list words with top, prefix or match first")
+	}
+	if s.offset == 0 {
+		return errors.New("this is the first page")
+	}
+	s.offset = max(s.offset-s.pageSize, 0)
+	s.show()
+	return nil
+}
+
+// Show the page of the given number, the first page is 1
+func (s *session) page(_ context.Context, args []string) error {
+	if s.list == nil {
+		return errors.New("there is no list to page through, list words with top, prefix or match first")
+	}
+	pages := max((len(s.list)+s.pageSize-1)/s.pageSize, 1)
+	if len(args) != 1 {
+		return errors.New("usage: page <n>")
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 || n > pages {
+		return fmt.Errorf("the page has to be between 1 and %d, not %q", pages, args[0])
+	}
+	s.offset = (n - 1) * s.pageSize
+	s.show()
+	return nil
+}
+
+// Print the page of the last list starting at offset, with the rank of every word
+func (s *session) show() {
+	if len(s.list) == 0 {
+		fmt.Fprintln(s.out, "no words")
+		return
+	}
+	end := min(s.offset+s.pageSize, len(s.list))
+	for _, i := range s.list[s.offset:end] {
+		e := s.entries[i]
+		fmt.Fprintf(s.out, "%6d. %s - %d\n", i+1, e.Word, e.Freq)
+	}
+	fmt.Fprintf(s.out, "words %d-%d of %d, page %d of %d\n", s.offset+1, end, len(s.list), s.offset/s.pageSize+1, (len(s.list)+s.pageSize-1)/s.pageSize)
+}
+
+// List the stop words, or add or remove the words of the arguments and count the words again
+func (s *session) stop(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		stopWords := s.wfc.StopWords()
+		fmt.Fprintf(s.out, "%d stop words: %s\n", len(stopWords), strings.Join(stopWords, ", "))
+		return nil
+	}
+
+	words := s.normalize(args[1:])
+	if len(words) == 0 {
+		return errors.New("usage: stop [add|remove <word>...]")
+	}
+	switch strings.ToLower(args[0]) {
+	case "add":
+		s.wfc.AddStopWords(words...)
+	case "remove":
+		s.wfc.RemoveStopWords(words...)
+	default:
+		return errors.New("usage: stop [add|remove <word>...]")
+	}
+
+	err := s.recount(ctx, nil)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(s.out, "%d words ranked\n", len(s.entries))
+	return nil
+}
+
+// Print the lines read so far, numbered from the oldest
+func (s *session) printHistory(_ context.Context, _ []string) error {
+	if s.history == nil {
+		return nil
+	}
+	for i, line := range s.history() {
+		fmt.Fprintf(s.out, "%5d  %s\n", i+1, line)
+	}
+	return nil
+}
+
+// Print the commands and what they do
+func (s *session) help(_ context.Context, _ []string) error {
+	for _, c := range commands {
+		fmt.Fprintf(s.out, "  %-28s %s\n", c.usage, c.help)
+	}
+	return nil
+}
+
+// Return the candidates completing the word that starts at index start of line, which ends at the cursor.
+// The first word is a command, and the words after it are words of the ranking or stop words, depending on the command
+func (s *session) complete(line []rune, start int) []string {
+	before := strings.Fields(string(line[:start]))
+	word := string(line[start:])
+	if len(before) == 0 {
+		names := make([]string, 0, len(commands))
+		for _, c := range commands {
+			if strings.HasPrefix(c.name, word) {
+				names = append(names, c.name)
+			}
+		}
+		return names
+	}
+
+	switch strings.ToLower(before[0]) {
+	case "count", "prefix":
+		return withPrefix(s.words, word)
+	case "stop":
+		if len(before) == 1 {
+			return withPrefix([]string{"add", "remove"}, word)
+		}
+		if strings.ToLower(before[1]) == "remove" {
+			return withPrefix(s.wfc.StopWords(), word)
+		}
+		return withPrefix(s.words, word)
+	}
+	return nil
+}
+
+// Return the words of the sorted slice words that start with prefix
+func withPrefix(words []string, prefix string) []string {
+	i, _ := slices.BinarySearch(words, prefix)
+	j := i
+	for j < len(words) && strings.HasPrefix(words[j], prefix) {
+		j++
+	}
+	return words[i:j]
+}
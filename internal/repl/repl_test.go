@@ -0,0 +1,180 @@
+package repl
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"flag"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/report"
+	"github.com/R0Xps/exercises-in-style-go/internal/styles/things"
+	"github.com/R0Xps/exercises-in-style-go/internal/tokens"
+)
+
+// Return a new session on the first example input, which lists 3 words at a time
+func newTestSession(t *testing.T) (*session, *bytes.Buffer) {
+	t.Helper()
+	filter := tokens.NewFilter()
+	output := report.NewOptions(filter)
+	output.Top = 3
+	var out bytes.Buffer
+	wfc, err := things.NewWordFrequencyController(filepath.Join("..", "..", "examples", "stop_words.txt"), []string{filepath.Join("..", "..", "examples", "input", "input1.txt")}, filter, nil, output, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newSession(wfc, filter, output, &out)
+	err = s.recount(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, &out
+}
+
+func TestSession(t *testing.T) {
+	s, out := newTestSession(t)
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"count Live the nothing", []string{"live - 2, rank ", "the is a stop word", "nothing isn't counted"}},
+		{"top 2", []string{"     1. ", "     2. ", "words 1-2 of "}},
+		{"next", []string{"     3. ", "page 2 of "}},
+		{"prev", []string{"     1. ", "page 1 of "}},
+		{"page 0", []string{"error: the page has to be between 1 and "}},
+		{"prefix liv", []string{"live - 2", "words 1-1 of 1, page 1 of 1"}},
+		{"match ^l.v", []string{"live - 2"}},
+		{"match (", []string{"error: error parsing regexp"}},
+		{"stop add live", []string{" words ranked"}},
+		{"count live", []string{"live is a stop word"}},
+		{"next", []string{"error: there is no list to page through"}},
+		{"stop remove live", []string{" words ranked"}},
+		{"count live", []string{"live - 2, rank "}},
+		{"unknown", []string{`error: unknown command "unknown"`}},
+	}
+	for _, test := range tests {
+		out.Reset()
+		_, err := s.execute(context.Background(), test.line)
+		if err != nil {
+			out.WriteString("error: " + err.Error())
+		}
+		for _, want := range test.want {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("%q: %q doesn't contain %q", test.line, out.String(), want)
+			}
+		}
+	}
+
+	// The words are filtered once when the input is read, so counting them again doesn't count their tokens again
+	stats := s.filter.Stats()
+	if _, err := s.execute(context.Background(), "stop add live"); err != nil {
+		t.Fatal(err)
+	}
+	if s.filter.Stats() != stats {
+		t.Errorf("the filter counted %+v tokens after a recount, want %+v", s.filter.Stats(), stats)
+	}
+
+	quit, err := s.execute(context.Background(), "quit")
+	if !quit || err != nil {
+		t.Errorf("quit didn't end the session: %v", err)
+	}
+}
+
+func TestLoop(t *testing.T) {
+	s, out := newTestSession(t)
+	reader := &scanReader{scanner: bufio.NewScanner(strings.NewReader("count live\n\nhistory\nquit\ncount live\n")), history: []string{"top"}}
+	s.history = reader.History
+	err := s.loop(context.Background(), reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The lines after quit aren't read, and the history starts with the history it was given
+	if strings.Count(out.String(), "live - 2") != 1 || !strings.Contains(out.String(), "    1  top\n    2  count live\n    3  history\n") {
+		t.Errorf("unexpected output %q", out.String())
+	}
+}
+
+func TestComplete(t *testing.T) {
+	s, _ := newTestSession(t)
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"pre", []string{"prefix", "prev"}},
+		{"count liv", []string{"live"}},
+		{"stop ", []string{"add", "remove"}},
+		{"stop remove abou", []string{"about"}},
+		{"top 1", nil},
+	}
+	for _, test := range tests {
+		line := []rune(test.line)
+		start := strings.LastIndexByte(test.line, ' ') + 1
+		got := s.complete(line, start)
+		if !slices.Equal(got, test.want) {
+			t.Errorf("%q: got %v, want %v", test.line, got, test.want)
+		}
+	}
+}
+
+func TestEditor(t *testing.T) {
+	keys := strings.Join([]string{
+		// Typing, moving the cursor and deleting
+		"cnt\x1b[D\x1b[Dou\x1b[F\x7ft\r",
+		// Ctrl-W deletes the word before the cursor, and Ctrl-A and Ctrl-K the whole line
+		"count mr darcy\x17\x01\x0bprefix d\r",
+		// Tab completes the command, and the word once it's the only candidate
+		"co\tdar\t\r",
+		// The history is browsed with the up and down keys, and the line being typed is kept
+		"top\x1b[A\x1b[A\x1b[B\x1b[B\x7fp\r",
+		// Ctrl-C discards the line, and Ctrl-D ends the input on an empty line
+		"next\x03\x04",
+	}, "")
+	var out bytes.Buffer
+	e := newEditor(strings.NewReader(keys), &out, "> ", nil)
+	e.complete = func(line []rune, start int) []string {
+		if start == 0 {
+			return withPrefix([]string{"count", "prefix"}, string(line))
+		}
+		return withPrefix([]string{"darcy", "mr"}, string(line[start:]))
+	}
+
+	var lines []string
+	for {
+		line, err := e.ReadLine()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, line)
+	}
+	want := []string{"count", "prefix d", "count darcy", "top", ""}
+	if !slices.Equal(lines, want) {
+		t.Errorf("got lines %q, want %q", lines, want)
+	}
+	if !slices.Equal(e.history, want[:4]) {
+		t.Errorf("got history %q", e.history)
+	}
+}
+
+func TestRegisterFlags(t *testing.T) {
+	// Every parse gets its own options, so a run doesn't keep the history file of the one before it
+	first := flag.NewFlagSet("repl", flag.ContinueOnError)
+	opts := registerFlags(first).(*options)
+	if err := first.Parse([]string{"--history", "first"}); err != nil {
+		t.Fatal(err)
+	}
+	second := flag.NewFlagSet("repl", flag.ContinueOnError)
+	other := registerFlags(second).(*options)
+	if err := second.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	if opts.historyFile != "first" || other.historyFile == "first" {
+		t.Errorf("got the history files %q and %q", opts.historyFile, other.historyFile)
+	}
+}
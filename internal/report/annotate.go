@@ -0,0 +1,74 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The statistics that can be added to every entry with --annotate
+var annotationNames = []string{"share", "per-million", "coverage"}
+
+// The csv/tsv column each annotation is written to
+var annotationColumns = map[string]string{
+	"share":       "relative",
+	"per-million": "per-million",
+	"coverage":    "cumulative",
+}
+
+// The headers of the annotations in the formats that have headers meant to be read by people
+var annotationHeaders = map[string]string{
+	"share":       "Share",
+	"per-million": "Per million",
+	"coverage":    "Coverage",
+}
+
+// Annotation holds the statistics of a ranked word, all relative to the counted tokens, which are the tokens left after the filter and the stop words
+type Annotation struct {
+	// Share is the fraction of the counted tokens the word makes up
+	Share float64
+	// PerMillion is the number of times the word appears per million counted tokens
+	PerMillion float64
+	// Coverage is the fraction of the counted tokens made up by all the entries up to and including this one
+	Coverage float64
+}
+
+// Return the annotations of the given entries, counted is the number of counted tokens
+func annotate(entries []Entry, counted int) []Annotation {
+	annotations := make([]Annotation, len(entries))
+	if counted == 0 {
+		return annotations
+	}
+
+	cumulative := 0
+	for i, e := range entries {
+		cumulative += e.Freq
+		annotations[i] = Annotation{
+			Share:      float64(e.Freq) / float64(counted),
+			PerMillion: 1e6 * float64(e.Freq) / float64(counted),
+			Coverage:   float64(cumulative) / float64(counted),
+		}
+	}
+	return annotations
+}
+
+// Return the value of the annotation with the given name formatted for people, shares are written as percentages
+func (a Annotation) Format(name string) string {
+	switch name {
+	case "share":
+		return fmt.Sprintf("%.2f%%", 100*a.Share)
+	case "per-million":
+		return fmt.Sprintf("%.1f", a.PerMillion)
+	case "coverage":
+		return fmt.Sprintf("%.2f%%", 100*a.Coverage)
+	}
+	return ""
+}
+
+// Return the annotations of the entry at index i of the report as "{share: n%, per-million: n, coverage: n%}", with only the annotations that were asked for
+func (r *Report) formatAnnotations(i int) string {
+	parts := make([]string, len(r.Annotate))
+	for j, name := range r.Annotate {
+		parts[j] = name + ": " + r.Annotations[i].Format(name)
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
@@ -0,0 +1,80 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/terminal"
+)
+
+// defaultWidth is the width used by the bars format when the output isn't a terminal and COLUMNS isn't set
+const defaultWidth = 80
+
+// minBarWidth is the narrowest the bars can get, even if the words and counts take up the whole line
+const minBarWidth = 10
+
+// Unicode blocks used to draw the bars, the last bar of a line can end with a partial block so bars have a resolution of an eighth of a column
+const fullBlock = "█"
+
+var partialBlocks = []string{"", "▏", "▎", "▍", "▌", "▋", "▊", "▉"}
+
+// Write the report as horizontal bars scaled to the width of the terminal, with the words and counts in aligned columns.
+// Unicode block characters are used when writing to a terminal, otherwise the bars are drawn with '#' so they survive being piped or redirected
+func writeBars(w io.Writer, r *Report, _ *Options) error {
+	width, isTerminal := terminal.Width(w)
+	if !isTerminal || width <= 0 {
+		width = defaultWidth
+		if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+			width = columns
+		}
+	}
+
+	// The annotations get a column each between the count and the bar
+	annotations := make([][]string, len(r.Entries))
+	annotationWidths := make([]int, len(r.Annotate))
+	wordWidth, countWidth := 0, 0
+	for i, e := range r.Entries {
+		wordWidth = max(wordWidth, utf8.RuneCountInString(r.Word(e)))
+		countWidth = max(countWidth, len(strconv.Itoa(e.Freq)))
+		annotations[i] = make([]string, len(r.Annotate))
+		for j, name := range r.Annotate {
+			annotations[i][j] = r.Annotations[i].Format(name)
+			annotationWidths[j] = max(annotationWidths[j], len(annotations[i][j]))
+		}
+	}
+	barWidth := width - wordWidth - countWidth - 2
+	for _, w := range annotationWidths {
+		barWidth -= w + 1
+	}
+	barWidth = max(minBarWidth, barWidth)
+
+	for i, e := range r.Entries {
+		// The first entry has the highest frequency, so its bar takes up the whole width
+		length := float64(e.Freq) / float64(r.Entries[0].Freq) * float64(barWidth)
+		word := r.Word(e)
+		padding := strings.Repeat(" ", wordWidth-utf8.RuneCountInString(word))
+		line := fmt.Sprintf("%s%s %*d ", word, padding, countWidth, e.Freq)
+		for j, annotation := range annotations[i] {
+			line += fmt.Sprintf("%*s ", annotationWidths[j], annotation)
+		}
+		line += drawBar(length, isTerminal)
+		_, err := fmt.Fprintln(w, strings.TrimRight(line, " "))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Return a bar that is the given number of columns long, using Unicode blocks if unicode is true, otherwise '#' rounded to whole columns
+func drawBar(length float64, unicode bool) string {
+	if !unicode {
+		return strings.Repeat("#", int(length+0.5))
+	}
+	eighths := int(length*8 + 0.5)
+	return strings.Repeat(fullBlock, eighths/8) + partialBlocks[eighths%8]
+}
@@ -0,0 +1,124 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"strings"
+)
+
+// Dimensions of the word cloud, in pixels
+const (
+	cloudWidth   = 800
+	cloudHeight  = 500
+	cloudMinFont = 12
+	cloudMaxFont = 72
+	// cloudPadding is the space kept between the boxes of two words
+	cloudPadding = 2
+	// cloudCharWidth is the width of a character relative to the font size. Every word is stretched to exactly that width with textLength, so the layout doesn't depend on the font the viewer has
+	cloudCharWidth = 0.6
+	// cloudSpiralStep is how much the spiral's radius grows with every radian, and cloudSpiralEnd is the angle after which a word that didn't fit is dropped
+	cloudSpiralStep = 2.0
+	cloudSpiralEnd  = 400.0
+)
+
+// The colors the words are drawn with
+var cloudColors = []string{"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd", "#8c564b"}
+
+// cloudWord is a word placed in the cloud, X and Y are the center of its box, and index is the index of its entry in the report
+type cloudWord struct {
+	index         int
+	Word          string
+	X, Y          float64
+	Width, Height float64
+	FontSize      float64
+	Color         string
+}
+
+// Check if the boxes of the two words overlap, including the padding between them
+func (w cloudWord) overlaps(o cloudWord) bool {
+	return math.Abs(w.X-o.X)*2 < w.Width+o.Width+2*cloudPadding && math.Abs(w.Y-o.Y)*2 < w.Height+o.Height+2*cloudPadding
+}
+
+// Check if the box of the word is entirely inside the cloud
+func (w cloudWord) inside() bool {
+	return w.X-w.Width/2 >= 0 && w.X+w.Width/2 <= cloudWidth && w.Y-w.Height/2 >= 0 && w.Y+w.Height/2 <= cloudHeight
+}
+
+// Write the top entries of the report as an SVG word cloud, where the font size of every word is proportional to its frequency.
+// Words are placed from the most frequent one outwards along a spiral starting at the center, at the first position where they don't overlap a word that was already placed.
+// The layout only depends on the entries and the seed in the options, so the same report always gives the same cloud
+func writeCloud(w io.Writer, r *Report, opts *Options) error {
+	words := layoutCloud(r, opts.Seed)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\" font-family=\"sans-serif\">\n", cloudWidth, cloudHeight, cloudWidth, cloudHeight)
+	for _, word := range words {
+		// The annotations of a word are shown as a tooltip
+		title := ""
+		if len(r.Annotate) > 0 {
+			title = "<title>" + xmlEscaper.Replace(word.Word+" "+r.formatAnnotations(word.index)) + "</title>"
+		}
+		fmt.Fprintf(&b, "<text x=\"%.1f\" y=\"%.1f\" font-size=\"%.1f\" fill=\"%s\" text-anchor=\"middle\" dominant-baseline=\"central\" textLength=\"%.1f\" lengthAdjust=\"spacingAndGlyphs\">%s%s</text>\n",
+			word.X, word.Y, word.FontSize, word.Color, word.Width, xmlEscaper.Replace(word.Word), title)
+	}
+	b.WriteString("</svg>\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Return the placed words of the cloud, words that don't fit anywhere are left out
+func layoutCloud(r *Report, seed int64) []cloudWord {
+	if len(r.Entries) == 0 {
+		return nil
+	}
+
+	rng := rand.New(rand.NewPCG(uint64(seed), uint64(seed)))
+	maxFreq := r.Entries[0].Freq
+	minFreq := r.Entries[len(r.Entries)-1].Freq
+
+	placed := make([]cloudWord, 0, len(r.Entries))
+	for i, e := range r.Entries {
+		size := float64(cloudMaxFont)
+		if maxFreq > minFreq {
+			size = cloudMinFont + float64(cloudMaxFont-cloudMinFont)*float64(e.Freq-minFreq)/float64(maxFreq-minFreq)
+		}
+		word := cloudWord{
+			index:    i,
+			Word:     r.Word(e),
+			Width:    cloudCharWidth * size * float64(len(r.Word(e))),
+			Height:   size,
+			FontSize: size,
+			Color:    cloudColors[rng.IntN(len(cloudColors))],
+		}
+
+		// Every word starts the spiral at a different angle, so the words don't all end up on the same side of the center
+		phase := rng.Float64() * 2 * math.Pi
+		for t := 0.0; t < cloudSpiralEnd; t += 0.1 {
+			radius := cloudSpiralStep * t
+			word.X = cloudWidth/2 + radius*math.Cos(t+phase)
+			// The cloud is wider than it is high, so the spiral is flattened to match
+			word.Y = cloudHeight/2 + radius*math.Sin(t+phase)*cloudHeight/cloudWidth
+			if word.inside() && !overlapsAny(word, placed) {
+				placed = append(placed, word)
+				break
+			}
+		}
+	}
+	return placed
+}
+
+// Check if the word overlaps any of the placed words
+func overlapsAny(word cloudWord, placed []cloudWord) bool {
+	for _, p := range placed {
+		if word.overlaps(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// xmlEscaper escapes the characters that have a meaning in XML
+var xmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\"", "&quot;", "'", "&apos;")
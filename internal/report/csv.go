@@ -0,0 +1,108 @@
+package report
+
+import (
+	"encoding/csv"
+	"io"
+	"slices"
+	"strconv"
+)
+
+// The optional columns of the csv and tsv formats, the word column is always written
+var columnNames = []string{"rank", "count", "relative", "per-million", "cumulative"}
+
+// Write the report as comma-separated values
+func writeCSV(w io.Writer, r *Report, opts *Options) error {
+	return writeTable(w, ',', r, tableColumns(r, opts))
+}
+
+// Write the report as tab-separated values
+func writeTSV(w io.Writer, r *Report, opts *Options) error {
+	return writeTable(w, '\t', r, tableColumns(r, opts))
+}
+
+// Return the chosen columns of the table followed by the columns of the annotations, annotations that are already one of the chosen columns aren't repeated
+func tableColumns(r *Report, opts *Options) []string {
+	columns := slices.Clone(opts.Columns)
+	for _, name := range r.Annotate {
+		column := annotationColumns[name]
+		if !slices.Contains(columns, column) {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// Write the report as a table with a header row, using the given separator. The rank column always comes first when it's used, followed by the word and the rest of the chosen columns.
+// There is also a count column for each input when there are several of them.
+// The relative column is the share of the counted tokens that the word makes up, the per-million column is the same share per million counted tokens, and the cumulative column is the share of all the words up to and including it
+func writeTable(w io.Writer, separator rune, r *Report, columns []string) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = separator
+
+	// Each column has a header and a function returning its value for the entry at a rank, given the cumulative frequency up to that rank
+	type column struct {
+		header string
+		value  func(rank int, e Entry, cumulative int) string
+	}
+
+	table := []column{{"word", func(_ int, e Entry, _ int) string { return r.Word(e) }}}
+	for _, name := range columns {
+		switch name {
+		case "rank":
+			table = slices.Insert(table, 0, column{"rank", func(rank int, _ Entry, _ int) string { return strconv.Itoa(rank) }})
+		case "count":
+			table = append(table, column{"count", func(_ int, e Entry, _ int) string { return strconv.Itoa(e.Freq) }})
+		case "relative":
+			table = append(table, column{"relative_frequency", func(_ int, e Entry, _ int) string { return formatShare(e.Freq, r.Counted) }})
+		case "per-million":
+			table = append(table, column{"per_million", func(_ int, e Entry, _ int) string { return formatPerMillion(e.Freq, r.Counted) }})
+		case "cumulative":
+			table = append(table, column{"cumulative_share", func(_ int, _ Entry, cumulative int) string { return formatShare(cumulative, r.Counted) }})
+		}
+	}
+	if len(r.Inputs) > 1 {
+		for d, input := range r.Inputs {
+			table = append(table, column{"count:" + input, func(_ int, e Entry, _ int) string { return strconv.Itoa(e.Documents[d]) }})
+		}
+	}
+
+	record := make([]string, len(table))
+	for i, c := range table {
+		record[i] = c.header
+	}
+	err := writer.Write(record)
+	if err != nil {
+		return err
+	}
+
+	cumulative := 0
+	for i, e := range r.Entries {
+		cumulative += e.Freq
+		for j, c := range table {
+			record[j] = c.value(i+1, e, cumulative)
+		}
+		err = writer.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// Return part/total formatted as a decimal fraction, or 0 if total is 0
+func formatShare(part, total int) string {
+	if total == 0 {
+		return "0"
+	}
+	return strconv.FormatFloat(float64(part)/float64(total), 'f', 6, 64)
+}
+
+// Return part/total per million, with two decimals, or 0 if total is 0
+func formatPerMillion(part, total int) string {
+	if total == 0 {
+		return "0"
+	}
+	return strconv.FormatFloat(1e6*float64(part)/float64(total), 'f', 2, 64)
+}
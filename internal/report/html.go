@@ -0,0 +1,231 @@
+package report
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/failure"
+)
+
+//go:embed html.tmpl
+var htmlSource string
+
+// htmlTemplate renders the html format, everything it needs (styles, scripts and charts) is inlined so the file can be opened offline
+var htmlTemplate = template.Must(template.New("report").Parse(htmlSource))
+
+// Dimensions of the charts in the html format, in pixels
+const (
+	chartWidth  = 720
+	chartLabels = 130
+	barHeight   = 18
+	barGap      = 4
+	plotSize    = 420
+	plotMargin  = 50
+)
+
+// htmlPage is the data the html template is executed with
+type htmlPage struct {
+	*Report
+	ElapsedMs    float64
+	StopWordList []string
+	Headers      []string
+	Rows         []htmlRow
+	Bars         htmlBars
+	Plot         htmlPlot
+}
+
+// htmlRow is a row of the ranking table
+type htmlRow struct {
+	Rank        int
+	Word        string
+	Count       int
+	Annotations []string
+	Variants    string
+	Casing      string
+}
+
+// htmlBars is the bar chart of the top entries
+type htmlBars struct {
+	Height int
+	Bars   []htmlBar
+}
+
+// htmlBar is a single bar of the bar chart, its coordinates are in the chart's pixels
+type htmlBar struct {
+	Word   string
+	Count  int
+	Y      int
+	Width  float64
+	LabelY int
+}
+
+// htmlPlot is the log-log plot of the frequency of each word against its rank.
+// The plotting area starts at Margin and ends at End on both axes, and the whole chart is Width pixels wide and high
+type htmlPlot struct {
+	Width  int
+	Margin int
+	End    int
+	Center int
+	Points []htmlPoint
+	XTicks []htmlTick
+	YTicks []htmlTick
+}
+
+// htmlPoint is a point of the log-log plot
+type htmlPoint struct {
+	X, Y float64
+}
+
+// htmlTick is a decade tick on one of the axes of the log-log plot
+type htmlTick struct {
+	Label string
+	Pos   float64
+}
+
+// Write the report as a single self-contained HTML page with a sortable table, a bar chart of the top entries, a rank/frequency plot and the run's metadata
+func writeHTML(w io.Writer, r *Report, _ *Options) error {
+	page := htmlPage{
+		Report:    r,
+		ElapsedMs: float64(r.Elapsed.Microseconds()) / 1000,
+		Rows:      make([]htmlRow, len(r.Entries)),
+		Bars:      newHTMLBars(r),
+		Plot:      newHTMLPlot(r.Frequencies),
+	}
+
+	if r.StopWordsFile != "" {
+		stopWords, err := readStopWords(r.StopWordsFile)
+		if err != nil {
+			return failure.Wrap(failure.StopWords, fmt.Errorf("reading stop words: %w", err))
+		}
+		page.StopWordList = stopWords
+	}
+
+	// The table has a column for each annotation, and shows the share of each word if no annotations were asked for
+	annotations := r.Annotate
+	if len(annotations) == 0 {
+		annotations = []string{"share"}
+	}
+	for _, name := range annotations {
+		page.Headers = append(page.Headers, annotationHeaders[name])
+	}
+
+	for i, e := range r.Entries {
+		page.Rows[i] = htmlRow{
+			Rank:        i + 1,
+			Word:        r.Word(e),
+			Count:       e.Freq,
+			Annotations: make([]string, len(annotations)),
+		}
+		for j, name := range annotations {
+			page.Rows[i].Annotations[j] = r.Annotations[i].Format(name)
+		}
+		if len(e.Variants) > 0 {
+			page.Rows[i].Variants = formatVariants(e.Variants)
+		}
+		if r.PreserveCase && e.Casing != nil {
+			page.Rows[i].Casing = formatCasing(e.Casing)
+		}
+	}
+
+	return htmlTemplate.Execute(w, page)
+}
+
+// Return the bar chart of the top entries of the report, the longest bar is the first one and takes the whole width of the chart
+func newHTMLBars(r *Report) htmlBars {
+	bars := htmlBars{
+		Height: len(r.Entries)*(barHeight+barGap) + barGap,
+		Bars:   make([]htmlBar, len(r.Entries)),
+	}
+	if len(r.Entries) == 0 {
+		return bars
+	}
+
+	// Leave some room after the longest bar for its count
+	scale := float64(chartWidth-chartLabels-60) / float64(r.Entries[0].Freq)
+	for i, e := range r.Entries {
+		y := barGap + i*(barHeight+barGap)
+		bars.Bars[i] = htmlBar{
+			Word:   r.Word(e),
+			Count:  e.Freq,
+			Y:      y,
+			Width:  math.Max(1, float64(e.Freq)*scale),
+			LabelY: y + barHeight - 5,
+		}
+	}
+	return bars
+}
+
+// Return the log-log plot of the given frequencies against their rank. Points that fall on the same pixel are only drawn once, which keeps the page small for large rankings
+func newHTMLPlot(freqs []int) htmlPlot {
+	plot := htmlPlot{
+		Width:  plotSize + 2*plotMargin,
+		Margin: plotMargin,
+		End:    plotMargin + plotSize,
+		Center: plotMargin + plotSize/2,
+	}
+	if len(freqs) == 0 || freqs[0] == 0 {
+		return plot
+	}
+
+	maxRank := decadeAbove(len(freqs))
+	maxFreq := decadeAbove(freqs[0])
+	x := func(rank int) float64 {
+		return plotMargin + float64(plotSize)*math.Log10(float64(rank))/math.Log10(float64(maxRank))
+	}
+	y := func(freq int) float64 {
+		return plotMargin + float64(plotSize)*(1-math.Log10(float64(freq))/math.Log10(float64(maxFreq)))
+	}
+
+	seen := make(map[[2]int]bool)
+	for i, freq := range freqs {
+		if freq <= 0 {
+			continue
+		}
+		point := htmlPoint{X: x(i + 1), Y: y(freq)}
+		pixel := [2]int{int(point.X), int(point.Y)}
+		if seen[pixel] {
+			continue
+		}
+		seen[pixel] = true
+		plot.Points = append(plot.Points, point)
+	}
+
+	for decade := 1; decade <= maxRank; decade *= 10 {
+		plot.XTicks = append(plot.XTicks, htmlTick{Label: fmt.Sprint(decade), Pos: x(decade)})
+	}
+	for decade := 1; decade <= maxFreq; decade *= 10 {
+		plot.YTicks = append(plot.YTicks, htmlTick{Label: fmt.Sprint(decade), Pos: y(decade)})
+	}
+	return plot
+}
+
+// Return the smallest power of ten that is greater than n, and at least 10 so the axes are never empty
+func decadeAbove(n int) int {
+	decade := 10
+	for decade <= n {
+		decade *= 10
+	}
+	return decade
+}
+
+// Read the comma-separated stop words file at the given path, and return its words in the order they appear
+func readStopWords(path string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+
+	words := make([]string, 0)
+	for _, word := range strings.Split(string(data), ",") {
+		if word = strings.TrimSpace(word); word != "" {
+			words = append(words, word)
+		}
+	}
+	return words, nil
+}
@@ -0,0 +1,110 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// SchemaVersion is the version of the document written by the json format, it is increased whenever a field is removed or changes its meaning.
+// Version 2 leaves out the token counts the style couldn't tell, and stop_words_removed no longer includes the evicted tokens
+const SchemaVersion = 2
+
+// jsonDocument is the document written by the json format, it is the same for every style
+type jsonDocument struct {
+	SchemaVersion int      `json:"schema_version"`
+	Style         string   `json:"style"`
+	Inputs        []string `json:"inputs"`
+	ElapsedMs     float64  `json:"elapsed_ms"`
+	// The token counts other than CountedTokens are left out when the style couldn't tell them
+	TotalTokens      *int        `json:"total_tokens,omitempty"`
+	FilteredTokens   *int        `json:"filtered_tokens,omitempty"`
+	StopWordsRemoved *int        `json:"stop_words_removed,omitempty"`
+	EvictedTokens    *int        `json:"evicted_tokens,omitempty"`
+	CountedTokens    int         `json:"counted_tokens"`
+	UniqueWords      int         `json:"unique_words"`
+	Entries          []jsonEntry `json:"entries"`
+}
+
+// jsonEntry is a single ranked word in the json format
+type jsonEntry struct {
+	Rank     int         `json:"rank,omitempty"`
+	Word     string      `json:"word"`
+	Count    int         `json:"count"`
+	Variants []jsonEntry `json:"variants,omitempty"`
+	Casing   *jsonCasing `json:"casing,omitempty"`
+	// Documents holds the count of the word in each input, in the same order as the inputs. It is only written when there are several inputs
+	Documents []int `json:"documents,omitempty"`
+	// The annotations are only written when they are asked for, shares are fractions between 0 and 1
+	Share      *float64 `json:"share,omitempty"`
+	PerMillion *float64 `json:"per_million,omitempty"`
+	Coverage   *float64 `json:"coverage,omitempty"`
+}
+
+// jsonCasing is the casing distribution of a word in the json format, it is only written when the report preserves case
+type jsonCasing struct {
+	Lower      int  `json:"lower"`
+	Title      int  `json:"title"`
+	Upper      int  `json:"upper"`
+	Mixed      int  `json:"mixed"`
+	ProperNoun bool `json:"proper_noun"`
+}
+
+// Write the report as an indented JSON document
+func writeJSON(w io.Writer, r *Report, _ *Options) error {
+	doc := jsonDocument{
+		SchemaVersion: SchemaVersion,
+		Style:         r.Style,
+		Inputs:        r.Inputs,
+		ElapsedMs:     float64(r.Elapsed.Microseconds()) / 1000,
+		CountedTokens: r.Counted,
+		UniqueWords:   r.UniqueWords,
+		Entries:       make([]jsonEntry, len(r.Entries)),
+	}
+	if r.StatsKnown {
+		doc.TotalTokens = &r.Tokens
+		doc.FilteredTokens = &r.Filtered
+		doc.StopWordsRemoved = &r.StopWords
+		doc.EvictedTokens = &r.Evicted
+	}
+	for i, e := range r.Entries {
+		doc.Entries[i] = r.jsonEntry(e)
+		doc.Entries[i].Rank = i + 1
+		for _, name := range r.Annotate {
+			a := r.Annotations[i]
+			switch name {
+			case "share":
+				doc.Entries[i].Share = &a.Share
+			case "per-million":
+				doc.Entries[i].PerMillion = &a.PerMillion
+			case "coverage":
+				doc.Entries[i].Coverage = &a.Coverage
+			}
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// Convert an entry (and its variants) to its json representation
+func (r *Report) jsonEntry(e Entry) jsonEntry {
+	entry := jsonEntry{
+		Word:      r.Word(e),
+		Count:     e.Freq,
+		Documents: e.Documents,
+	}
+	for _, v := range e.Variants {
+		entry.Variants = append(entry.Variants, r.jsonEntry(v))
+	}
+	if r.PreserveCase && e.Casing != nil {
+		entry.Casing = &jsonCasing{
+			Lower:      e.Casing.Lower,
+			Title:      e.Casing.Title,
+			Upper:      e.Casing.Upper,
+			Mixed:      e.Casing.Mixed,
+			ProperNoun: e.Casing.IsProperNoun(),
+		}
+	}
+	return entry
+}
@@ -0,0 +1,61 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/casing"
+)
+
+// Write the report as "word - freq" lines, which is the original output of every style.
+// Merged aliases are followed by their per-variant breakdown, the casing distribution is added when the report preserves case, and the annotations when there are any
+func writePlain(w io.Writer, r *Report, _ *Options) error {
+	for i, e := range r.Entries {
+		line := fmt.Sprint(r.Word(e), " - ", e.Freq)
+		if len(e.Variants) > 0 {
+			line += " " + formatVariants(e.Variants)
+		}
+		if r.PreserveCase {
+			line += " " + formatCasing(e.Casing)
+		}
+		if len(r.Annotate) > 0 {
+			line += " " + r.formatAnnotations(i)
+		}
+		_, err := fmt.Fprintln(w, line)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Return the per-variant breakdown of an entry as "(variant: freq, ...)"
+func formatVariants(variants []Entry) string {
+	parts := make([]string, len(variants))
+	for i, v := range variants {
+		parts[i] = fmt.Sprintf("%s: %d", v.Word, v.Freq)
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+// Return the casing distribution of an entry as "[lower: n, Title: n, UPPER: n, Mixed: n]" (only non-zero counts), followed by a marker if it is probably a proper noun
+func formatCasing(s *casing.Stats) string {
+	counts := []struct {
+		name  string
+		count int
+	}{{"lower", s.Lower}, {"Title", s.Title}, {"UPPER", s.Upper}, {"Mixed", s.Mixed}}
+
+	parts := make([]string, 0, len(counts))
+	for _, c := range counts {
+		if c.count > 0 {
+			parts = append(parts, fmt.Sprintf("%s: %d", c.name, c.count))
+		}
+	}
+
+	str := "[" + strings.Join(parts, ", ") + "]"
+	if s.IsProperNoun() {
+		str += " proper noun"
+	}
+	return str
+}
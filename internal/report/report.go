@@ -0,0 +1,477 @@
+// Package report contains the last stage shared by every style, which takes the sorted word frequencies a style computed and prints them
+package report
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/aliases"
+	"github.com/R0Xps/exercises-in-style-go/internal/casing"
+	"github.com/R0Xps/exercises-in-style-go/internal/failure"
+	"github.com/R0Xps/exercises-in-style-go/internal/limits"
+	"github.com/R0Xps/exercises-in-style-go/internal/tokens"
+)
+
+// Entry stores a word-frequency pair, Variants holds the surface forms that were merged into the word and their own frequencies
+type Entry struct {
+	Word     string
+	Freq     int
+	Variants []Entry
+	// Casing is the casing distribution of the word in the input files, it is only set when one of the casing options is used
+	Casing *casing.Stats
+	// Documents holds the frequency of the word in each input file, it is only set when there are several input files
+	Documents []int
+}
+
+// Result is what a style hands to the report stage: the name of the style, and the words it counted in each input file
+type Result struct {
+	Style     string
+	Documents []Document
+	// Stats overrides the token counts of the token filter and the limits, it is used by styles that don't pass every word through the filter on every run
+	Stats *Stats
+	// Casing overrides the casing recorded by the options while the style read its input files, it is used by styles that store the casing with the words they counted
+	Casing map[string]*casing.Stats
+	// StatsUnknown is set by styles that can't tell their token counts, the report then leaves out every count but the counted tokens instead of computing them from the counts of the token filter
+	StatsUnknown bool
+}
+
+// Stats are the token counts of a run: the tokens read from the inputs, how many of them the token filter kept, and how many of the kept ones were evicted by the limits after they were counted
+type Stats struct {
+	Tokens  int
+	Kept    int
+	Evicted int
+}
+
+// Document holds all the words counted in a single input file, sorted by frequency in descending order
+type Document struct {
+	Input   string
+	Entries []Entry
+}
+
+// Report is a result after it's been processed according to the options, it holds everything the output formats write
+type Report struct {
+	Style   string
+	Inputs  []string
+	Elapsed time.Duration
+	// Tokens is the number of tokens read from the inputs, Filtered is how many of them the token filter rejected, StopWords is how many were removed as stop words,
+	// Evicted is how many were counted but forgotten when the limits evicted their words, and Counted is how many were counted in the end.
+	// StatsKnown is false when the style couldn't tell its token counts, Counted is the only one of them that is set then, and the formats leave the others out
+	StatsKnown bool
+	Tokens     int
+	Filtered   int
+	StopWords  int
+	Evicted    int
+	Counted    int
+	// UniqueWords is the number of distinct words in the ranking, before it's cut down to the top entries
+	UniqueWords int
+	// Entries holds the top entries of the ranking, sorted by frequency in descending order
+	Entries []Entry
+	// PreserveCase is set when the words are written in their most common casing, and the entries have their casing distribution
+	PreserveCase bool
+	// StopWordsFile is the path of the stop words file used by the style
+	StopWordsFile string
+	// Annotate holds the names of the statistics the formats add to every entry, and Annotations holds these statistics for each entry
+	Annotate    []string
+	Annotations []Annotation
+	// Frequencies holds the frequency of every word in the ranking, including the ones that were cut from Entries, in descending order
+	Frequencies []int
+}
+
+// Options configure how the entries are processed before they are printed
+type Options struct {
+	// Top is the maximum number of entries that are printed
+	Top int
+	// AliasesFile is the path of an alias file used to merge variant spellings into a canonical term, empty means no aliases
+	AliasesFile string
+	// PreserveCase prints every word in its most common casing along with its casing distribution, and marks probable proper nouns
+	PreserveCase bool
+	// OnlyProper and ExcludeProper keep only (or drop) the words that are probably proper nouns
+	OnlyProper    bool
+	ExcludeProper bool
+	// Format is the name of the output format
+	Format string
+	// Columns are the optional columns written by the csv and tsv formats, in order
+	Columns []string
+	// Annotate holds the names of the statistics added to every entry, in order
+	Annotate []string
+	// Template is the path of a text/template file used by the template format
+	Template string
+	// Seed is the seed of the random numbers used to lay out the word cloud
+	Seed int64
+	// StopWordsFile is the path of the stop words file, it is set by the style after parsing its arguments and only used in the report's metadata
+	StopWordsFile string
+	// Partial prints the words counted so far when a run is interrupted, see Interrupted
+	Partial bool
+	// Limits are the limits of the run, which count the occurrences of the words the style evicted, it is nil when there are no limits
+	Limits *limits.Limits
+
+	// filter is the token filter used by the style, the casing of the input files is scanned using the same token characters, and its counts are used in the report
+	filter *tokens.Filter
+	// casing records the casing of the input files as the style reads them, when one of the casing options is used
+	casing *casing.Recorder
+	// start is the time the options were created, which every style does first, so it's used to measure the run time
+	start time.Time
+}
+
+// The output formats, by the name used to select them
+var formats = map[string]func(io.Writer, *Report, *Options) error{
+	"plain":     writePlain,
+	"json":      writeJSON,
+	"csv":       writeCSV,
+	"tsv":       writeTSV,
+	"html":      writeHTML,
+	"bars":      writeBars,
+	"svg-cloud": writeCloud,
+	"template":  writeTemplate,
+}
+
+// Create and return a pointer to a new Options object with the default values used by every style, filter has to be the token filter the style splits its input with
+func NewOptions(filter *tokens.Filter) *Options {
+	return &Options{
+		Top:     25,
+		Format:  "plain",
+		Columns: []string{"rank", "count"},
+		Seed:    1,
+		filter:  filter,
+		casing:  casing.NewRecorder(),
+		start:   time.Now(),
+	}
+}
+
+// Create and return a pointer to a copy of the options for another run, which uses the given token filter and measures its run time from now
+func (o *Options) Clone(filter *tokens.Filter) *Options {
+	clone := *o
+	clone.filter = filter
+	clone.casing = casing.NewRecorder()
+	clone.start = time.Now()
+	return &clone
+}
+
+// Register the command-line flags that configure the report on the given FlagSet
+func (o *Options) RegisterFlags(fs *flag.FlagSet) {
+	fs.IntVar(&o.Top, "top", o.Top, "print the `n` most frequent words")
+	fs.StringVar(&o.AliasesFile, "aliases", o.AliasesFile, "merge variant spellings into canonical terms using the alias `file`")
+	fs.BoolVar(&o.PreserveCase, "case", o.PreserveCase, "print words in their most common casing, with their casing distribution and a proper noun marker")
+	fs.BoolVar(&o.OnlyProper, "only-proper", o.OnlyProper, "only print words that are probably proper nouns")
+	fs.BoolVar(&o.ExcludeProper, "exclude-proper", o.ExcludeProper, "don't print words that are probably proper nouns")
+	fs.Var((*formatValue)(&o.Format), "format", "output `format`, one of: "+strings.Join(formatNames(), ", "))
+	fs.Func("template", "write the report using the text/template `file`, this implies --format template", func(path string) error {
+		o.Template = path
+		o.Format = "template"
+		return nil
+	})
+	fs.Int64Var(&o.Seed, "seed", o.Seed, "`seed` of the svg-cloud layout, the same seed always gives the same cloud")
+	fs.Var(&listValue{&o.Columns, columnNames, "column"}, "columns", "comma-separated `list` of optional csv/tsv columns, any of: "+strings.Join(columnNames, ", "))
+	fs.Var(&listValue{&o.Annotate, annotationNames, "annotation"}, "annotate", "comma-separated `list` of statistics added to every entry, any of: "+strings.Join(annotationNames, ", "))
+	fs.BoolVar(&o.Partial, "partial", o.Partial, "when the run is interrupted, print the ranking of the words counted so far")
+}
+
+// formatValue is a flag.Value that only accepts the names of known output formats
+type formatValue string
+
+func (f *formatValue) String() string {
+	return string(*f)
+}
+
+func (f *formatValue) Set(name string) error {
+	if _, ok := formats[name]; !ok {
+		return fmt.Errorf("unknown format %q", name)
+	}
+	*f = formatValue(name)
+	return nil
+}
+
+// listValue is a flag.Value holding a comma-separated list of names, which all have to be in allowed
+type listValue struct {
+	list    *[]string
+	allowed []string
+	// what is the kind of name in the list, used in error messages
+	what string
+}
+
+func (l *listValue) String() string {
+	if l.list == nil {
+		return ""
+	}
+	return strings.Join(*l.list, ",")
+}
+
+func (l *listValue) Set(value string) error {
+	list := make([]string, 0)
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !slices.Contains(l.allowed, name) {
+			return fmt.Errorf("unknown %s %q", l.what, name)
+		}
+		list = append(list, name)
+	}
+	*l.list = list
+	return nil
+}
+
+// Return the names of all output formats in alphabetical order
+func formatNames() []string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Check that the options can be used together, and return an error describing the problem if they can't
+func (o *Options) Validate() error {
+	if o.Top < 1 {
+		return fmt.Errorf("--top has to be at least 1")
+	}
+	if o.OnlyProper && o.ExcludeProper {
+		return fmt.Errorf("--only-proper and --exclude-proper can't be used together")
+	}
+	return nil
+}
+
+// Check if the casing of the input files has to be recorded
+func (o *Options) NeedsCasing() bool {
+	return o.PreserveCase || o.OnlyProper || o.ExcludeProper
+}
+
+// Record the casing of data, which is text read from an input file before it is normalized, if one of the casing options is used.
+// Every style calls it with the text it counts the words of, right after reading it, so the casing matches the words counted
+func (o *Options) RecordCasing(data []byte) {
+	if o == nil || !o.NeedsCasing() {
+		return
+	}
+	o.casing.Record(data, o.filter.IsTokenChar)
+}
+
+// Process the given result according to the options, then write it to w in the chosen output format
+func Print(w io.Writer, result *Result, opts *Options) error {
+	write, ok := formats[opts.Format]
+	if !ok {
+		return fmt.Errorf("unknown format %q", opts.Format)
+	}
+
+	report, err := Process(result, opts)
+	if err != nil {
+		return err
+	}
+
+	return write(w, report, opts)
+}
+
+// Handle a run that was interrupted by err: print the result holding the words counted so far to w if the options ask for partial results, then return err so the run still fails
+func Interrupted(w io.Writer, result *Result, opts *Options, err error) error {
+	if !opts.Partial {
+		return err
+	}
+	printErr := Print(w, result, opts)
+	if printErr != nil {
+		return errors.Join(err, printErr)
+	}
+	return err
+}
+
+// Process the given result according to the options, merging aliases, adding casing information, filtering proper nouns and keeping only the top entries
+func Process(result *Result, opts *Options) (*Report, error) {
+	err := opts.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	filterStats := opts.filter.Stats()
+	stats := Stats{Tokens: filterStats.Tokens, Kept: filterStats.Kept, Evicted: opts.Limits.Evicted()}
+	if result.Stats != nil {
+		stats = *result.Stats
+	}
+	report := &Report{
+		Style:         result.Style,
+		Inputs:        make([]string, len(result.Documents)),
+		StatsKnown:    !result.StatsUnknown,
+		PreserveCase:  opts.PreserveCase,
+		StopWordsFile: opts.StopWordsFile,
+		Annotate:      opts.Annotate,
+	}
+	for i, doc := range result.Documents {
+		report.Inputs[i] = doc.Input
+	}
+
+	entries := mergeDocuments(result.Documents)
+	for _, e := range entries {
+		report.Counted += e.Freq
+	}
+	if report.StatsKnown {
+		report.Tokens = stats.Tokens
+		report.Filtered = stats.Tokens - stats.Kept
+		report.Evicted = stats.Evicted
+		report.StopWords = stats.Kept - stats.Evicted - report.Counted
+	}
+
+	if opts.NeedsCasing() {
+		casingStats := result.Casing
+		if casingStats == nil {
+			casingStats = opts.casing.Stats()
+		}
+		entries = addCasing(entries, casingStats)
+	}
+
+	if opts.AliasesFile != "" {
+		m, err := aliases.Load(opts.AliasesFile)
+		if err != nil {
+			return nil, failure.Wrap(failure.Input, fmt.Errorf("reading aliases: %w", err))
+		}
+		entries = MergeAliases(entries, m)
+	}
+
+	if opts.OnlyProper || opts.ExcludeProper {
+		entries = filterProperNouns(entries, opts.OnlyProper)
+	}
+
+	report.UniqueWords = len(entries)
+	report.Frequencies = make([]int, len(entries))
+	for i, e := range entries {
+		report.Frequencies[i] = e.Freq
+	}
+	report.Entries = entries[:min(opts.Top, len(entries))]
+	report.Annotations = annotate(report.Entries, report.Counted)
+	report.Elapsed = time.Since(opts.start)
+
+	return report, nil
+}
+
+// Return the word of the entry as it should be written, which is its most common casing if the report preserves case
+func (r *Report) Word(e Entry) string {
+	if r.PreserveCase && e.Casing != nil {
+		return e.Casing.Apply(e.Word)
+	}
+	return e.Word
+}
+
+// Return the documents without the entries of the given words, for the styles that evict words from the counts of documents they already converted to report documents.
+// The entries of the given documents are left as they are
+func WithoutWords(documents []Document, words []string) []Document {
+	if len(words) == 0 {
+		return documents
+	}
+	kept := make([]Document, len(documents))
+	for i, doc := range documents {
+		kept[i] = Document{Input: doc.Input, Entries: slices.DeleteFunc(slices.Clone(doc.Entries), func(e Entry) bool {
+			return slices.Contains(words, e.Word)
+		})}
+	}
+	return kept
+}
+
+// Combine the entries of all documents into a single slice, keeping the frequency of every word in each document when there are several of them.
+// The slice is sorted by frequency in descending order, and words with the same frequency are sorted alphabetically so that every style writes the same report
+func mergeDocuments(docs []Document) []Entry {
+	merged := make([]Entry, 0)
+	index := make(map[string]int)
+	for d, doc := range docs {
+		for _, e := range doc.Entries {
+			i, ok := index[e.Word]
+			if !ok {
+				i = len(merged)
+				index[e.Word] = i
+				merged = append(merged, Entry{Word: e.Word})
+				if len(docs) > 1 {
+					merged[i].Documents = make([]int, len(docs))
+				}
+			}
+			merged[i].Freq += e.Freq
+			if len(docs) > 1 {
+				merged[i].Documents[d] += e.Freq
+			}
+		}
+	}
+	slices.SortFunc(merged, byRank)
+
+	return merged
+}
+
+// Merge all entries that are surface forms of the same canonical term into a single entry, keeping the frequency of every variant.
+// The returned slice is sorted by frequency in descending order, then alphabetically
+func MergeAliases(entries []Entry, m aliases.Map) []Entry {
+	merged := make([]Entry, 0, len(entries))
+	index := make(map[string]int)
+	for _, e := range entries {
+		canonical := m.Canonical(e.Word)
+		i, ok := index[canonical]
+		if !ok {
+			i = len(merged)
+			index[canonical] = i
+			merged = append(merged, Entry{Word: canonical})
+		}
+		merged[i].Freq += e.Freq
+		merged[i].Variants = append(merged[i].Variants, Entry{Word: e.Word, Freq: e.Freq, Casing: e.Casing, Documents: e.Documents})
+		if e.Documents != nil {
+			if merged[i].Documents == nil {
+				merged[i].Documents = make([]int, len(e.Documents))
+			}
+			for d, freq := range e.Documents {
+				merged[i].Documents[d] += freq
+			}
+		}
+		if e.Casing != nil {
+			if merged[i].Casing == nil {
+				merged[i].Casing = &casing.Stats{}
+			}
+			merged[i].Casing.Add(*e.Casing)
+		}
+	}
+
+	for i := range merged {
+		// A term that was only seen under its canonical form has no breakdown to show
+		if len(merged[i].Variants) == 1 && merged[i].Variants[0].Word == merged[i].Word {
+			merged[i].Variants = nil
+		}
+		slices.SortStableFunc(merged[i].Variants, byFreq)
+	}
+	slices.SortFunc(merged, byRank)
+
+	return merged
+}
+
+// Return a copy of the entries with the Casing field set from the given stats
+func addCasing(entries []Entry, stats map[string]*casing.Stats) []Entry {
+	annotated := make([]Entry, len(entries))
+	for i, e := range entries {
+		e.Casing = &casing.Stats{}
+		if s, ok := stats[e.Word]; ok {
+			*e.Casing = *s
+		}
+		annotated[i] = e
+	}
+	return annotated
+}
+
+// Return the entries that are probably proper nouns if proper is true, otherwise the entries that are not
+func filterProperNouns(entries []Entry, proper bool) []Entry {
+	filtered := make([]Entry, 0, len(entries))
+	for _, e := range entries {
+		if e.Casing.IsProperNoun() == proper {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
+
+// Compare function used to sort entries by frequency in descending order
+func byFreq(i, j Entry) int {
+	return j.Freq - i.Freq
+}
+
+// Compare function used to sort entries by frequency in descending order, and alphabetically when they have the same frequency
+func byRank(i, j Entry) int {
+	if i.Freq != j.Freq {
+		return j.Freq - i.Freq
+	}
+	return strings.Compare(i.Word, j.Word)
+}
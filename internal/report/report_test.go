@@ -0,0 +1,260 @@
+package report
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/aliases"
+	"github.com/R0Xps/exercises-in-style-go/internal/casing"
+	"github.com/R0Xps/exercises-in-style-go/internal/tokens"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+func TestMergeAliases(t *testing.T) {
+	m := aliases.Map{"lizzy": "elizabeth", "eliza": "elizabeth", "elizabeth": "elizabeth", "colour": "color", "color": "color"}
+	entries := []Entry{{Word: "darcy", Freq: 10}, {Word: "elizabeth", Freq: 8}, {Word: "lizzy", Freq: 3}, {Word: "colour", Freq: 2}, {Word: "eliza", Freq: 1}}
+
+	got := MergeAliases(entries, m)
+	want := []Entry{
+		{Word: "elizabeth", Freq: 12, Variants: []Entry{{Word: "elizabeth", Freq: 8}, {Word: "lizzy", Freq: 3}, {Word: "eliza", Freq: 1}}},
+		{Word: "darcy", Freq: 10},
+		{Word: "color", Freq: 2, Variants: []Entry{{Word: "colour", Freq: 2}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	r := &Report{
+		Inputs:  []string{"a.txt", "b,c.txt"},
+		Counted: 4,
+		Entries: []Entry{{Word: "darcy", Freq: 3, Documents: []int{1, 2}}, {Word: "jane", Freq: 1, Documents: []int{0, 1}}},
+	}
+	opts := &Options{Columns: []string{"count", "rank", "cumulative"}}
+
+	var b strings.Builder
+	err := writeCSV(&b, r, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "rank,word,count,cumulative_share,count:a.txt,\"count:b,c.txt\"\n" +
+		"1,darcy,3,0.750000,1,2\n" +
+		"2,jane,1,1.000000,0,1\n"
+	if b.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
+
+func TestWriteHTMLSelfContained(t *testing.T) {
+	r := &Report{
+		Style:       "test",
+		Inputs:      []string{"<input>.txt"},
+		Counted:     4,
+		Entries:     []Entry{{Word: "darcy", Freq: 3}, {Word: "jane", Freq: 1}},
+		Frequencies: []int{3, 1},
+	}
+	r.Annotations = annotate(r.Entries, r.Counted)
+
+	var b strings.Builder
+	err := writeHTML(&b, r, &Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page := b.String()
+	for _, want := range []string{"<td>darcy</td>", "&lt;input&gt;.txt", "<circle", "75.00%"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page doesn't contain %q", want)
+		}
+	}
+	for _, external := range []string{"src=", "href=", "@import", "url("} {
+		if strings.Contains(page, external) {
+			t.Errorf("page references an external asset with %q", external)
+		}
+	}
+}
+
+func TestWriteBars(t *testing.T) {
+	t.Setenv("COLUMNS", "20")
+	r := &Report{
+		Entries: []Entry{{Word: "darcy", Freq: 20}, {Word: "jane", Freq: 5}},
+	}
+
+	var b strings.Builder
+	err := writeBars(&b, r, &Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "darcy 20 ###########\n" +
+		"jane   5 ###\n"
+	if b.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b.String(), want)
+	}
+
+	if got := drawBar(2.5, true); got != "██▌" {
+		t.Errorf("drawBar(2.5) = %q, want %q", got, "██▌")
+	}
+}
+
+func TestWriteCloudGolden(t *testing.T) {
+	r := &Report{}
+	for i, word := range strings.Fields("mr elizabeth very darcy such mrs much more bennet bingley jane miss one know before herself though well never sister soon think now time good") {
+		r.Entries = append(r.Entries, Entry{Word: word, Freq: 800 - 30*i})
+	}
+
+	placed := layoutCloud(r, 1)
+	for i, w := range placed {
+		if !w.inside() {
+			t.Errorf("%q is outside the cloud", w.Word)
+		}
+		for _, o := range placed[:i] {
+			if w.overlaps(o) {
+				t.Errorf("%q overlaps %q", w.Word, o.Word)
+			}
+		}
+	}
+
+	var b strings.Builder
+	err := writeCloud(&b, r, &Options{Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden := filepath.Join("testdata", "cloud.svg")
+	if *update {
+		err = os.WriteFile(golden, []byte(b.String()), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != string(want) {
+		t.Errorf("cloud doesn't match %s, run the test with -update if the change is intended:\n%s", golden, b.String())
+	}
+
+	b.Reset()
+	err = writeCloud(&b, r, &Options{Seed: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.String() == string(want) {
+		t.Error("a different seed gave the same cloud")
+	}
+}
+
+func TestWriteTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.tmpl")
+	err := os.WriteFile(path, []byte(`{{humanize .Counted}}{{range .Entries}}|{{ordinal .Rank}} {{padRight 6 .Word}}{{padLeft 5 .Count}} {{percent .Count $.Counted}} {{printf "%.2f" .Coverage}}{{end}}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &Report{
+		Counted: 1200,
+		Entries: []Entry{{Word: "darcy", Freq: 900}, {Word: "jane", Freq: 300}},
+	}
+	r.Annotations = annotate(r.Entries, r.Counted)
+
+	var b strings.Builder
+	err = writeTemplate(&b, r, &Options{Template: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "1,200|1st darcy   900 75.00% 0.75|2nd jane    300 25.00% 1.00"
+	if b.String() != want {
+		t.Errorf("got %q, want %q", b.String(), want)
+	}
+}
+
+func TestProcessStats(t *testing.T) {
+	documents := []Document{{Input: "a.txt", Entries: []Entry{{Word: "darcy", Freq: 3}, {Word: "jane", Freq: 1}}}}
+	opts := NewOptions(tokens.NewFilter())
+
+	r, err := Process(&Result{Documents: documents, Stats: &Stats{Tokens: 10, Kept: 9, Evicted: 2}}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.StatsKnown || r.Tokens != 10 || r.Filtered != 1 || r.Evicted != 2 || r.StopWords != 3 || r.Counted != 4 {
+		t.Errorf("unexpected counts %+v", r)
+	}
+
+	// The counts of a style that can't tell them aren't computed from the counts of the token filter, which didn't see its words
+	r, err = Process(&Result{Documents: documents, StatsUnknown: true}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.StatsKnown || r.Tokens != 0 || r.StopWords != 0 || r.Counted != 4 {
+		t.Errorf("unexpected counts %+v", r)
+	}
+	var b strings.Builder
+	err = writeJSON(&b, r, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{"total_tokens", "filtered_tokens", "stop_words_removed", "evicted_tokens"} {
+		if strings.Contains(b.String(), field) {
+			t.Errorf("%s is written for unknown counts:\n%s", field, b.String())
+		}
+	}
+	if !strings.Contains(b.String(), `"counted_tokens": 4`) {
+		t.Errorf("counted_tokens isn't written:\n%s", b.String())
+	}
+	// Leaving the counts out changed the schema
+	if !strings.Contains(b.String(), `"schema_version": 2`) {
+		t.Errorf("unexpected schema version:\n%s", b.String())
+	}
+}
+
+func TestProcessProperNouns(t *testing.T) {
+	documents := []Document{{Input: "a.txt", Entries: []Entry{{Word: "the", Freq: 3}, {Word: "darcy", Freq: 2}, {Word: "jane", Freq: 1}}}}
+	text := []byte("The letter came. Then Darcy wrote to the aunt of Jane, and the reply reached Darcy")
+
+	tests := []struct {
+		onlyProper, excludeProper bool
+		want                      []string
+	}{
+		{true, false, []string{"darcy", "jane"}},
+		{false, true, []string{"the"}},
+	}
+	for _, tt := range tests {
+		opts := NewOptions(tokens.NewFilter())
+		opts.OnlyProper, opts.ExcludeProper = tt.onlyProper, tt.excludeProper
+		// The casing recorded while the input was read is used, the input file isn't read again
+		opts.RecordCasing(text)
+		r, err := Process(&Result{Documents: documents}, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var words []string
+		for _, e := range r.Entries {
+			words = append(words, e.Word)
+		}
+		if !slices.Equal(words, tt.want) {
+			t.Errorf("only-proper %v, exclude-proper %v: got %v, want %v", tt.onlyProper, tt.excludeProper, words, tt.want)
+		}
+	}
+
+	// The casing of the result is used instead of the recorded one, for the styles that store it
+	opts := NewOptions(tokens.NewFilter())
+	opts.OnlyProper = true
+	opts.RecordCasing(text)
+	r, err := Process(&Result{Documents: documents, Casing: map[string]*casing.Stats{"jane": {Title: 1, MidSentence: 1, CapitalizedMidSentence: 1}}}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Entries) != 1 || r.Entries[0].Word != "jane" {
+		t.Errorf("got %v, want only jane", r.Entries)
+	}
+}
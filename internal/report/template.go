@@ -0,0 +1,131 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/failure"
+)
+
+// templateDocument is the data a user template is executed with
+type templateDocument struct {
+	Style         string
+	Inputs        []string
+	StopWordsFile string
+	Elapsed       time.Duration
+	StatsKnown    bool
+	Tokens        int
+	Filtered      int
+	StopWords     int
+	Evicted       int
+	Counted       int
+	UniqueWords   int
+	Entries       []templateEntry
+}
+
+// templateEntry is a single ranked word in a user template, Rank and the annotations are 0 for variants
+type templateEntry struct {
+	Rank       int
+	Word       string
+	Count      int
+	Share      float64
+	PerMillion float64
+	Coverage   float64
+	Variants   []templateEntry
+	Documents  []int
+	Casing     string
+	ProperNoun bool
+}
+
+// The helper functions available in user templates
+var templateFuncs = template.FuncMap{
+	// padLeft and padRight pad a value with spaces to the given width, they take the width first so they can be used in pipelines: {{.Word | padRight 15}}
+	"padLeft": func(width int, v any) string {
+		s := fmt.Sprint(v)
+		return strings.Repeat(" ", max(0, width-utf8.RuneCountInString(s))) + s
+	},
+	"padRight": func(width int, v any) string {
+		s := fmt.Sprint(v)
+		return s + strings.Repeat(" ", max(0, width-utf8.RuneCountInString(s)))
+	},
+	// percent formats part as a percentage of total with two decimals
+	"percent": func(part, total int) string {
+		if total == 0 {
+			return "0.00%"
+		}
+		return fmt.Sprintf("%.2f%%", 100*float64(part)/float64(total))
+	},
+	// humanize formats a number with thousands separators, ordinal formats it as 1st, 2nd...
+	"humanize": func(n int) string {
+		return humanize.Comma(int64(n))
+	},
+	"ordinal": humanize.Ordinal,
+	// join joins a list of strings with the given separator
+	"join": func(sep string, s []string) string {
+		return strings.Join(s, sep)
+	},
+}
+
+// Write the report using the user template in the options
+func writeTemplate(w io.Writer, r *Report, opts *Options) error {
+	if opts.Template == "" {
+		return fmt.Errorf("the template format needs a template file, use --template")
+	}
+
+	t, err := template.New(filepath.Base(opts.Template)).Funcs(templateFuncs).ParseFiles(opts.Template)
+	if err != nil {
+		return failure.Wrap(failure.Input, fmt.Errorf("reading template: %w", err))
+	}
+
+	doc := templateDocument{
+		Style:         r.Style,
+		Inputs:        r.Inputs,
+		StopWordsFile: r.StopWordsFile,
+		Elapsed:       r.Elapsed,
+		StatsKnown:    r.StatsKnown,
+		Tokens:        r.Tokens,
+		Filtered:      r.Filtered,
+		StopWords:     r.StopWords,
+		Evicted:       r.Evicted,
+		Counted:       r.Counted,
+		UniqueWords:   r.UniqueWords,
+		Entries:       make([]templateEntry, len(r.Entries)),
+	}
+	for i, e := range r.Entries {
+		doc.Entries[i] = r.templateEntry(e)
+		doc.Entries[i].Rank = i + 1
+		doc.Entries[i].Share = r.Annotations[i].Share
+		doc.Entries[i].PerMillion = r.Annotations[i].PerMillion
+		doc.Entries[i].Coverage = r.Annotations[i].Coverage
+	}
+
+	err = t.Execute(w, doc)
+	if err != nil {
+		return fmt.Errorf("executing template: %w", err)
+	}
+	return nil
+}
+
+// Convert an entry (and its variants) to its template representation
+func (r *Report) templateEntry(e Entry) templateEntry {
+	entry := templateEntry{
+		Word:      r.Word(e),
+		Count:     e.Freq,
+		Documents: e.Documents,
+	}
+	for _, v := range e.Variants {
+		entry.Variants = append(entry.Variants, r.templateEntry(v))
+	}
+	if e.Casing != nil {
+		entry.Casing = formatCasing(e.Casing)
+		entry.ProperNoun = e.Casing.IsProperNoun()
+	}
+	return entry
+}
@@ -0,0 +1,64 @@
+package actors
+
+import (
+	"context"
+	"sync"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/cli"
+	"github.com/R0Xps/exercises-in-style-go/internal/explain"
+)
+
+// Style is the actors style, which is run by its own binary and by eis
+var Style = cli.Style{Name: "actors", Run: run, Follow: true, Explain: describe}
+
+func run(ctx context.Context, cfg *cli.Config) error {
+	// The token filter is configured by command-line flags and handed to the actors that read files
+	filter := cfg.Filter
+
+	// When the input files are followed, the controller cancels the run itself if it fails, so the data storage manager stops following them
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// sync.WaitGroup is used to ensure all goroutines are done before exiting the program
+	wg := new(sync.WaitGroup)
+
+	// Create the needed actors, start their goroutines, and send their initialization messages
+	wfm := NewWordFrequencyManager()
+	wg.Go(wfm.Start)
+	wfm.Send([]any{"init", cfg.InputFiles, cfg.Limits, cfg.Progress, cfg.Explain})
+
+	swm := NewStopWordManager()
+	wg.Go(swm.Start)
+	swm.Send([]any{"init", cfg.StopWordsFile, wfm, filter, cfg.Explain})
+
+	dsm := NewDataStorageManager()
+	wg.Go(dsm.Start)
+	dsm.Send([]any{"init", cfg.InputFiles, swm, filter, cfg.Limits, cfg.Progress, cfg.Follow, cfg.Explain, cfg.Output})
+
+	wfc := NewWordFrequencyController()
+	wg.Go(wfc.Start)
+	wfc.Send([]any{"run", ctx, dsm, cfg.Output, cfg.Stdout, cfg.InputFiles, cancel, cfg.Follow, cfg.Explain})
+
+	// This blocks until all goroutines are done, then the controller holds the error that stopped them if there is one
+	wg.Wait()
+	return wfc.err
+}
+
+// Declare the actors on the plan p in the order the words go through them, with the messages each of them receives and sends
+func describe(p *explain.Plan, _ *cli.Config) {
+	p.Stage("DataStorageManager",
+		"receives init(inputFiles, swm, filter, limits, progress, follow, plan, output), send_word_freqs(wfc, ctx), die",
+		"reads the input files, records their casing in the output options and normalizes them, then sends filter(word, doc) for each word kept by the token filter, and top25(wfc), or update(wfc) and done(wfc) when they are followed, to StopWordManager",
+		"forwards die to StopWordManager, and sends error(err) to WordFrequencyController if the input files can't be read")
+	p.Stage("StopWordManager",
+		"receives init(stopWordsFile, wfm, filter, plan), filter(word, doc), top25(wfc), update(wfc), done(wfc), die",
+		"sends word(word, doc) for each word that isn't a stop word to WordFrequencyManager",
+		"forwards top25, update, done and die to WordFrequencyManager, and sends error(err) to WordFrequencyController if the stop words can't be read")
+	p.Stage("WordFrequencyManager",
+		"receives init(inputFiles, limits, progress, plan), word(word, doc), top25(wfc), update(wfc), done(wfc), die",
+		"counts the words of each document, then sends top25(documents), update(documents), done or error(err) to WordFrequencyController")
+	p.Stage("WordFrequencyController",
+		"receives run(ctx, dsm, output, stdout, inputFiles, cancel, follow, plan), top25(documents), update(documents), done, error(err)",
+		"sends send_word_freqs(wfc, ctx) to DataStorageManager, prints the report, then sends die to DataStorageManager")
+	p.Note("Every actor is a goroutine with a mailbox of 100 messages, the sizes are the number of messages each of them received, by type")
+}
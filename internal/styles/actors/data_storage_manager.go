@@ -0,0 +1,215 @@
+package actors
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/explain"
+	"github.com/R0Xps/exercises-in-style-go/internal/failure"
+	"github.com/R0Xps/exercises-in-style-go/internal/follow"
+	"github.com/R0Xps/exercises-in-style-go/internal/limits"
+	"github.com/R0Xps/exercises-in-style-go/internal/progress"
+	"github.com/R0Xps/exercises-in-style-go/internal/report"
+	"github.com/R0Xps/exercises-in-style-go/internal/tokens"
+)
+
+// DataStorageManager handles everything related to the input file
+type DataStorageManager struct {
+	messages        chan []any
+	stopWordManager *StopWordManager
+	filter          *tokens.Filter
+	progress        *progress.Progress
+	data            []string
+	// follow holds the options of --follow, tails read the lines appended to the input files when they are followed, within the limits
+	follow *follow.Options
+	tails  []*follow.Tail
+	limits *limits.Limits
+	// plan counts the messages received by type when the plan of the run is explained
+	plan *explain.Plan
+	// output records the casing of the text read from the input files before it is normalized, when the report needs it
+	output *report.Options
+	// err is the error reading the input files, it is sent to the controller instead of the words
+	err error
+}
+
+// Create and return a pointer to a new DataStoragaManager object (actor)
+func NewDataStorageManager() *DataStorageManager {
+	dsm := &DataStorageManager{
+		messages: make(chan []any, 100),
+	}
+	return dsm
+}
+
+// Send a message to this actor
+func (dsm *DataStorageManager) Send(message []any) {
+	dsm.messages <- message
+}
+
+// This function runs in a goroutine and keeps running until a 'die' message is received
+func (dsm *DataStorageManager) Start() {
+	for msg := range dsm.messages {
+		dsm.dispatch(msg)
+		dsm.plan.Add("DataStorageManager", msg[0].(string)+" message", 1)
+		if msg[0] == "die" {
+			close(dsm.messages)
+		}
+	}
+}
+
+// Handle received messages, if they are a known type, run their appropriate functions, otherwise forward them to stopWordManager
+func (dsm *DataStorageManager) dispatch(message []any) {
+	switch message[0] {
+	case "init":
+		dsm.init(message[1:])
+	case "send_word_freqs":
+		dsm.processWords(message[1:])
+	default:
+		dsm.stopWordManager.Send(message)
+	}
+}
+
+// Initialize the DataStorageManager object with a StopWordManager and a token filter that are received in the message, and a string for each of the files in the paths received in the message as well, which is read and filtered from that file.
+// The files are read within the limits in the message, and the words sent from them are reported to the progress in the message. If the follow options in the message are enabled, the files are read up to their last line, and the lines appended to them later are read by processWords.
+// The messages received are counted on the plan in the message, and the casing of the text read is recorded in the report options in the message
+func (dsm *DataStorageManager) init(message []any) {
+	dsm.plan = message[6].(*explain.Plan)
+	dsm.output = message[7].(*report.Options)
+	inputFilePaths := message[0].([]string)
+	dsm.stopWordManager = message[1].(*StopWordManager)
+	dsm.filter = message[2].(*tokens.Filter)
+	l := message[3].(*limits.Limits)
+	dsm.progress = message[4].(*progress.Progress)
+	dsm.follow = message[5].(*follow.Options)
+	dsm.limits = l
+
+	if dsm.follow != nil && dsm.follow.Enabled {
+		for _, inputFilePath := range inputFilePaths {
+			tail := follow.NewTail(inputFilePath)
+			dsm.tails = append(dsm.tails, tail)
+			bytes, err := tail.Read(l)
+			if err != nil {
+				dsm.err = failure.Wrap(failure.Input, err)
+				return
+			}
+			dsm.output.RecordCasing(bytes)
+			dsm.data = append(dsm.data, dsm.filter.Normalize(bytes))
+		}
+		return
+	}
+
+	for _, inputFilePath := range inputFilePaths {
+		file, err := os.Open(filepath.Clean(inputFilePath))
+		if err != nil {
+			dsm.err = failure.Wrap(failure.Input, err)
+			return
+		}
+
+		bytes, err := l.ReadAll(inputFilePath, file)
+		closeErr := file.Close()
+		if err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			dsm.err = failure.Wrap(failure.Input, err)
+			return
+		}
+
+		dsm.output.RecordCasing(bytes)
+		dsm.data = append(dsm.data, dsm.filter.Normalize(bytes))
+	}
+}
+
+// Number of words sent between checks of the context of the run
+const checkInterval = 1024
+
+// Split each data string into words, then forward the ones kept by the token filter to stopWordManager to filter (along with the index of the document they came from), and send another message of type "top25" to a WordFrequencyManager through stopWordManager.
+// If the context in the message is canceled, no more words are sent, and the "top25" message is sent right away so the words counted so far reach the recipient.
+// When the input files are followed, an "update" message is sent instead of "top25", and the files keep being followed until the context is canceled.
+// If the input files couldn't be read, an "error" message is sent to the recipient instead
+func (dsm *DataStorageManager) processWords(message []any) {
+	recipient := message[0].(*WordFrequencyController)
+	ctx := message[1].(context.Context)
+	if dsm.err != nil {
+		recipient.Send([]any{"error", dsm.err})
+		if dsm.tails != nil {
+			dsm.stopWordManager.Send([]any{"done", recipient})
+		}
+		return
+	}
+
+	for doc, data := range dsm.data {
+		if !dsm.sendWords(ctx, doc, data) {
+			break
+		}
+	}
+	if dsm.tails == nil {
+		dsm.stopWordManager.Send([]any{"top25", recipient})
+		return
+	}
+	// The words of the files were sent, only the appended lines are kept from now on
+	dsm.data = nil
+	dsm.stopWordManager.Send([]any{"update", recipient})
+	dsm.followFiles(ctx, recipient)
+}
+
+// Forward the words of data, which is the normalized text of the document at index doc, that are kept by the token filter to stopWordManager, and return false if the context was canceled before all of them were sent
+func (dsm *DataStorageManager) sendWords(ctx context.Context, doc int, data string) bool {
+	words := strings.Fields(data)
+	sent := 0
+	for i, w := range words {
+		if !dsm.filter.Keep(w) {
+			continue
+		}
+		if sent%checkInterval == 0 {
+			dsm.progress.Checkpoint(doc, "sending word messages", int64(i), int64(len(words)))
+			if ctx.Err() != nil {
+				return false
+			}
+		}
+		dsm.stopWordManager.Send([]any{"filter", w, doc})
+		sent++
+	}
+	return true
+}
+
+// Read the lines appended to the input files every interval of the follow options and forward their words, followed by an "update" message so the recipient prints the new ranking, until the context is canceled.
+// A "done" message is sent then through the other actors, so it reaches the recipient after every word and "update" message. If a file can't be read anymore, an "error" message is sent to the recipient before it
+func (dsm *DataStorageManager) followFiles(ctx context.Context, recipient *WordFrequencyController) {
+	defer func() {
+		for _, tail := range dsm.tails {
+			_ = tail.Close()
+		}
+	}()
+	ticker := time.NewTicker(max(dsm.follow.Interval, 10*time.Millisecond))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			dsm.stopWordManager.Send([]any{"done", recipient})
+			return
+		case <-ticker.C:
+		}
+
+		updated := false
+		for doc, tail := range dsm.tails {
+			bytes, err := tail.Read(dsm.limits)
+			if err != nil {
+				recipient.Send([]any{"error", failure.Wrap(failure.Input, err)})
+				dsm.stopWordManager.Send([]any{"done", recipient})
+				return
+			}
+			if len(bytes) > 0 {
+				dsm.output.RecordCasing(bytes)
+				dsm.sendWords(ctx, doc, dsm.filter.Normalize(bytes))
+				updated = true
+			}
+		}
+		if updated {
+			dsm.stopWordManager.Send([]any{"update", recipient})
+		}
+	}
+}
@@ -1,12 +1,15 @@
-package main
+package actors
 
 import (
 	"io"
-	"log"
 	"os"
 	"path/filepath"
 	"slices"
 	"strings"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/explain"
+	"github.com/R0Xps/exercises-in-style-go/internal/failure"
+	"github.com/R0Xps/exercises-in-style-go/internal/tokens"
 )
 
 // StopWordsManager handles everything about stop words, starting at reading them from a file, up to filtering words and only forwarding non-stop words
@@ -14,6 +17,10 @@ type StopWordManager struct {
 	messages             chan []any
 	wordFrequencyManager *WordFrequencyManager
 	stopWords            []string
+	// plan counts the messages received by type when the plan of the run is explained
+	plan *explain.Plan
+	// err is the error reading the stop words file, it is sent to the controller instead of forwarding the "top25" message
+	err error
 }
 
 // Create and return a pointer to a new StopWordManager object (actor)
@@ -33,6 +40,7 @@ func (swm *StopWordManager) Send(message []any) {
 func (swm *StopWordManager) Start() {
 	for msg := range swm.messages {
 		swm.dispatch(msg)
+		swm.plan.Add("StopWordManager", msg[0].(string)+" message", 1)
 		if msg[0] == "die" {
 			close(swm.messages)
 		}
@@ -46,35 +54,59 @@ func (swm *StopWordManager) dispatch(message []any) {
 		swm.init(message[1:])
 	case "filter":
 		swm.filter(message[1:])
+	case "top25", "update":
+		swm.top25(message)
 	default:
 		swm.wordFrequencyManager.Send(message)
 	}
 }
 
-// Initializes the StopWordManager object with a WordFrequencyManager that is received in the message, and a slice of stop words that are read from a file in a path received in the message as well
+// Initializes the StopWordManager object with a WordFrequencyManager that is received in the message, and a slice of stop words that are read from a file in a path received in the message as well (and split using the token filter in the message).
+// The messages received are counted on the plan in the message
 func (swm *StopWordManager) init(message []any) {
+	swm.plan = message[3].(*explain.Plan)
 	stopWordsFilePath := message[0].(string)
 	swm.wordFrequencyManager = message[1].(*WordFrequencyManager)
+	filter := message[2].(*tokens.Filter)
 
 	file, err := os.Open(filepath.Clean(stopWordsFilePath))
 	if err != nil {
-		log.Fatal(err)
+		swm.err = failure.Wrap(failure.StopWords, err)
+		return
 	}
 
 	bytes, err := io.ReadAll(file)
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
 	if err != nil {
-		log.Fatal(err)
+		swm.err = failure.Wrap(failure.StopWords, err)
+		return
 	}
 
-	str := filterAndNormalize(bytes)
+	str := filter.Normalize(bytes)
 
 	swm.stopWords = strings.Fields(str)
 }
 
-// Filter received words and only forward non-stop words to wordFrequencyManager
+// Forward the "top25" or "update" message to wordFrequencyManager, or send an "error" message to its recipient if the stop words couldn't be read
+func (swm *StopWordManager) top25(message []any) {
+	if swm.err != nil {
+		message[1].(*WordFrequencyController).Send([]any{"error", swm.err})
+		return
+	}
+	swm.wordFrequencyManager.Send(message)
+}
+
+// Filter received words and only forward non-stop words to wordFrequencyManager, along with the index of their document.
+// Words are dropped if the stop words couldn't be read, since the run stops with that error
 func (swm *StopWordManager) filter(message []any) {
+	if swm.err != nil {
+		return
+	}
 	word := message[0].(string)
 	if !slices.Contains(swm.stopWords, word) {
-		swm.wordFrequencyManager.Send([]any{"word", word})
+		swm.wordFrequencyManager.Send([]any{"word", word, message[1]})
 	}
 }
@@ -0,0 +1,162 @@
+package actors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/explain"
+	"github.com/R0Xps/exercises-in-style-go/internal/failure"
+	"github.com/R0Xps/exercises-in-style-go/internal/follow"
+	"github.com/R0Xps/exercises-in-style-go/internal/report"
+	"github.com/R0Xps/exercises-in-style-go/internal/terminal"
+)
+
+// WordFrequencyController acts as the driver code for the term frequency task
+type WordFrequencyController struct {
+	messages           chan []any
+	ctx                context.Context
+	dataStorageManager *DataStorageManager
+	output             *report.Options
+	stdout             io.Writer
+	inputFilePaths     []string
+	// follow holds the options of --follow, cancel cancels the context of the run so the input files stop being followed, and updates is the number of rankings printed while following them
+	follow  *follow.Options
+	cancel  context.CancelFunc
+	updates int
+	// plan counts the messages received by type when the plan of the run is explained
+	plan *explain.Plan
+	// err is the error that stopped the actors, it is read after all of them are done
+	err error
+}
+
+// Create and return a pointer to a new WordFrequencyController object (actor)
+func NewWordFrequencyController() *WordFrequencyController {
+	wfc := &WordFrequencyController{
+		messages: make(chan []any, 100),
+	}
+	return wfc
+}
+
+// Send a message to this actor
+func (wfc *WordFrequencyController) Send(message []any) {
+	wfc.messages <- message
+}
+
+// This function runs in a goroutine and keeps running until either a 'die' message is received, or the final results are printed
+func (wfc *WordFrequencyController) Start() {
+	for msg := range wfc.messages {
+		wfc.dispatch(msg)
+		wfc.plan.Add("WordFrequencyController", msg[0].(string)+" message", 1)
+		if msg[0] == "die" {
+			close(wfc.messages)
+		}
+	}
+}
+
+// Handle received messages, if they are a known type, run their appropriate functions, otherwise the run fails with an internal error, since only the actors send messages to each other
+func (wfc *WordFrequencyController) dispatch(message []any) {
+	switch message[0] {
+	case "run":
+		wfc.run(message[1:])
+	case "top25":
+		wfc.display(message[1:])
+	case "update":
+		wfc.update(message[1:])
+	case "done":
+		wfc.done()
+	case "error":
+		wfc.fail(message[1:])
+	default:
+		wfc.fail([]any{failure.Wrap(failure.Internal, fmt.Errorf("unknown message type %q", message[0]))})
+	}
+}
+
+// Start the chain of messages leading to the execution of the term frequency task, the message also holds the context of the run, the options used to print the results, the writer they are printed to, the paths of the input files they are computed from, the function that cancels the context, the follow options, and the plan the messages received are counted on
+func (wfc *WordFrequencyController) run(message []any) {
+	wfc.plan = message[7].(*explain.Plan)
+	wfc.ctx = message[0].(context.Context)
+	wfc.dataStorageManager = message[1].(*DataStorageManager)
+	wfc.output = message[2].(*report.Options)
+	wfc.stdout = message[3].(io.Writer)
+	wfc.inputFilePaths = message[4].([]string)
+	wfc.cancel = message[5].(context.CancelFunc)
+	wfc.follow = message[6].(*follow.Options)
+	wfc.dataStorageManager.Send([]any{"send_word_freqs", wfc, wfc.ctx})
+}
+
+// Print the top (25 max) words and their frequencies in all documents.
+// If the context of the run was canceled, the words counted before that are only printed if the output options ask for partial results, and the run fails with the error of the context
+func (wfc *WordFrequencyController) display(message []any) {
+	result := wfc.result(message[0].([][]wordFreqEntry))
+	if wfc.ctx.Err() != nil {
+		wfc.err = report.Interrupted(wfc.stdout, result, wfc.output, wfc.ctx.Err())
+	} else {
+		wfc.err = report.Print(wfc.stdout, result, wfc.output)
+	}
+	wfc.stop()
+}
+
+// Print the ranking of the words counted so far while the input files are followed. On a terminal the screen is cleared first, so the ranking is redrawn in place, otherwise the rankings are separated by an empty line.
+// Rankings that arrive after an actor failed aren't printed, since the run is stopping
+func (wfc *WordFrequencyController) update(message []any) {
+	if wfc.err != nil {
+		return
+	}
+	if _, isTerminal := terminal.Width(wfc.stdout); isTerminal {
+		fmt.Fprint(wfc.stdout, "\x1b[H\x1b[2J")
+	} else if wfc.updates > 0 {
+		fmt.Fprintln(wfc.stdout)
+	}
+	wfc.updates++
+
+	err := report.Print(wfc.stdout, wfc.result(message[0].([][]wordFreqEntry)), wfc.output)
+	if err != nil {
+		wfc.fail([]any{err})
+	}
+}
+
+// Stop the actors once the data storage manager stopped following the input files. Following them is meant to end when the run is interrupted, so the run only fails if an actor failed or its timeout is over
+func (wfc *WordFrequencyController) done() {
+	if wfc.err == nil && errors.Is(wfc.ctx.Err(), context.DeadlineExceeded) {
+		wfc.err = wfc.ctx.Err()
+	}
+	wfc.stop()
+}
+
+// Return the result holding the given words and frequencies of each document
+func (wfc *WordFrequencyController) result(documents [][]wordFreqEntry) *report.Result {
+	result := &report.Result{Style: "actors"}
+	for i, inputFilePath := range wfc.inputFilePaths {
+		// Documents without any counted words are never seen by the WordFrequencyManager, so they might be missing from the end of the slice
+		entries := make([]report.Entry, 0)
+		if i < len(documents) {
+			for _, wf := range documents[i] {
+				entries = append(entries, report.Entry{Word: wf.word, Freq: wf.freq})
+			}
+		}
+		result.Documents = append(result.Documents, report.Document{Input: inputFilePath, Entries: entries})
+	}
+	return result
+}
+
+// Keep the error received from another actor, then stop all of them.
+// When the input files are followed, more messages are on their way until the data storage manager stops following them, so the first error is kept and the actors are stopped once "done" arrives
+func (wfc *WordFrequencyController) fail(message []any) {
+	if wfc.follow != nil && wfc.follow.Enabled {
+		if wfc.err == nil {
+			wfc.err = message[0].(error)
+		}
+		wfc.cancel()
+		return
+	}
+	wfc.err = message[0].(error)
+	wfc.stop()
+}
+
+// Stop all the actors, the "die" message goes through all of them starting with dataStorageManager
+func (wfc *WordFrequencyController) stop() {
+	wfc.dataStorageManager.Send([]any{"die"})
+	close(wfc.messages)
+}
@@ -0,0 +1,134 @@
+package actors
+
+import (
+	"slices"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/explain"
+	"github.com/R0Xps/exercises-in-style-go/internal/limits"
+	"github.com/R0Xps/exercises-in-style-go/internal/progress"
+)
+
+// WordFrequencyManager handles counting and sorting the words based on their frequencies, in each document separately
+type WordFrequencyManager struct {
+	messages chan []any
+	freq     []map[string]int
+	// inputFilePaths are the paths of the documents, and limits bounds how many distinct words are kept from all of them together
+	inputFilePaths []string
+	limits         *limits.Limits
+	// progress receives the number of word messages counted, and received is that number since the last time it was reported
+	progress *progress.Progress
+	received int
+	// plan counts the messages received by type when the plan of the run is explained
+	plan *explain.Plan
+	// err is the error of the limits when a document has too many distinct words, it is sent to the controller instead of the words
+	err error
+}
+
+// wordFreqEntry struct is used to store a word-frequency pair
+type wordFreqEntry struct {
+	word string
+	freq int
+}
+
+// Create and return a pointer to a new WordFrequencyManager object (actor)
+func NewWordFrequencyManager() *WordFrequencyManager {
+	wfm := &WordFrequencyManager{
+		messages: make(chan []any, 100),
+		freq:     make([]map[string]int, 0),
+	}
+	return wfm
+}
+
+// Send a message to this actor
+func (wfm *WordFrequencyManager) Send(message []any) {
+	wfm.messages <- message
+}
+
+// This function runs in a goroutine and keeps running until a 'die' message is received
+func (wfm *WordFrequencyManager) Start() {
+	for msg := range wfm.messages {
+		wfm.dispatch(msg)
+		wfm.plan.Add("WordFrequencyManager", msg[0].(string)+" message", 1)
+		if msg[0] == "die" {
+			close(wfm.messages)
+		}
+	}
+}
+
+// Handle received messages, if they are a known type, run their appropriate functions, otherwise ignore them
+func (wfm *WordFrequencyManager) dispatch(message []any) {
+	switch message[0] {
+	case "init":
+		wfm.init(message[1:])
+	case "word":
+		wfm.increment(message[1:])
+	case "top25", "update":
+		wfm.top25(message)
+	case "done":
+		message[1].(*WordFrequencyController).Send([]any{"done"})
+	}
+}
+
+// Initialize the WordFrequencyManager object with the paths of the documents, the limits, the progress, and the plan that are received in the message
+func (wfm *WordFrequencyManager) init(message []any) {
+	wfm.inputFilePaths = message[0].([]string)
+	wfm.limits = message[1].(*limits.Limits)
+	wfm.progress = message[2].(*progress.Progress)
+	wfm.plan = message[3].(*explain.Plan)
+}
+
+// Number of word messages counted between reports to the progress
+const reportInterval = 1024
+
+// Increments the frequency of a word in the freq map of its document, as long as the limits leave room for it, the words they evict to make room are removed from the maps of every document.
+// Words are dropped once the documents have too many distinct words for the limits, since the run stops with that error
+func (wfm *WordFrequencyManager) increment(message []any) {
+	if wfm.err != nil {
+		return
+	}
+	word := message[0].(string)
+	doc := message[1].(int)
+	for len(wfm.freq) <= doc {
+		wfm.freq = append(wfm.freq, make(map[string]int))
+	}
+	var evicted []string
+	evicted, wfm.err = wfm.limits.Admit(wfm.inputFilePaths[doc], word, 1)
+	if wfm.err == nil {
+		for _, evictedWord := range evicted {
+			for _, freq := range wfm.freq {
+				delete(freq, evictedWord)
+			}
+		}
+		wfm.freq[doc][word]++
+	}
+
+	wfm.received++
+	if wfm.received == reportInterval {
+		wfm.progress.AddTokens(int64(wfm.received))
+		wfm.progress.SetUniqueWords(len(wfm.freq[doc]))
+		wfm.received = 0
+	}
+}
+
+// Returns a slice for each document of all its words and their frequencies ordered by frequency in descending order, in a message of the same type as the one received ("top25" or "update"), or an "error" message if a document had too many distinct words
+func (wfm *WordFrequencyManager) top25(message []any) {
+	recipient := message[1].(*WordFrequencyController)
+	if wfm.err != nil {
+		recipient.Send([]any{"error", wfm.err})
+		return
+	}
+	documents := make([][]wordFreqEntry, 0)
+	for _, freq := range wfm.freq {
+		wordFreq := make([]wordFreqEntry, 0)
+		for k, v := range freq {
+			wordFreq = append(wordFreq, wordFreqEntry{k, v})
+		}
+
+		slices.SortFunc(wordFreq, func(i, j wordFreqEntry) int {
+			return j.freq - i.freq
+		})
+		documents = append(documents, wordFreq)
+	}
+
+	recipient.Send([]any{message[0], documents})
+}
@@ -0,0 +1,64 @@
+// Code generated by gen_descriptions.go from cmd/*/README.md; DO NOT EDIT.
+
+package styles
+
+// Descriptions holds the description of every style, by name
+var Descriptions = map[string]Description{
+	"actors": {
+		Title: "Actors",
+		Constraints: []string{
+			"The larger problem is decomposed into things that make sense for the problem domain.",
+			"Each thing has a queue meant for other things to place messages in it.",
+			"Each thing is a capsule of data that exposes only its ability to receive messages via the queue.",
+			"Each thing has its own thread of execution independent of the others.",
+		},
+	},
+	"map_reduce": {
+		Title: "Map Reduce",
+		Constraints: []string{
+			"Input data is divided in blocks.",
+			"A map function applies a given worker function to each block of data, potentially in parallel.",
+			"A reduce function takes the results of the many worker functions and recombines them into a coherent output.",
+		},
+	},
+	"monolithic": {
+		Title: "Monolithic",
+		Constraints: []string{
+			"No named abstractions.",
+			"No, or little, use of libraries.",
+		},
+	},
+	"persistent_tables": {
+		Title: "Persistent Tables",
+		Constraints: []string{
+			"The data exists beyond the execution of programs that use it, and is meant to be used by many different programs.",
+			"The data is stored in a way that makes it easier/faster to explore. For example: The input data of the problem is modeled as one or more series of domains, or types, of data. The concrete data is modeled as having components of several domains, establishing relationships between the application’s data and the domains identified.",
+			"The problem is solved by issuing queries over the data.",
+		},
+	},
+	"pipeline": {
+		Title: "Pipeline",
+		Constraints: []string{
+			"Larger problem is decomposed using functional abstraction. Functions take input and produce output.",
+			"No shared state between functions.",
+			"The larger problem is solved by composing functions one after the other, in pipeline, as a faithful reproduction of mathematical function composition f ◦ g (f after g).",
+		},
+	},
+	"quarantine": {
+		Title: "Quarantine",
+		Constraints: []string{
+			"Core program functions have no side effects of any kind, including IO.",
+			"All IO actions must be contained in computation sequences that are clearly separated from the pure functions.",
+			"All sequences that have IO must be called from the main program.",
+		},
+	},
+	"things": {
+		Title: "Things",
+		Constraints: []string{
+			"The larger problem is decomposed into things that make sense for the problem domain.",
+			"Each thing is a capsule of data that exposes procedures to the rest of the world.",
+			"Data is never accessed directly, only through these procedures.",
+			"Capsules can reappropriate procedures defined in other capsules.",
+		},
+	},
+}
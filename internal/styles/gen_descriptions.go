@@ -0,0 +1,45 @@
+//go:build ignore
+
+// This program generates descriptions.go from the README of every style in cmd, run it with go generate
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/styles"
+)
+
+func main() {
+	var b bytes.Buffer
+	b.WriteString("// Code generated by gen_descriptions.go from cmd/*/README.md; DO NOT EDIT.\n\n")
+	b.WriteString("package styles\n\n")
+	b.WriteString("// Descriptions holds the description of every style, by name\n")
+	b.WriteString("var Descriptions = map[string]Description{\n")
+	for _, s := range styles.All {
+		readme, err := os.ReadFile(filepath.Join("..", "..", "cmd", s.Name, "README.md"))
+		if err != nil {
+			log.Fatal(err)
+		}
+		d := styles.ParseDescription(readme)
+		fmt.Fprintf(&b, "%q: {\nTitle: %q,\nConstraints: []string{\n", s.Name, d.Title)
+		for _, c := range d.Constraints {
+			fmt.Fprintf(&b, "%q,\n", c)
+		}
+		b.WriteString("},\n},\n")
+	}
+	b.WriteString("}\n")
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = os.WriteFile("descriptions.go", src, 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
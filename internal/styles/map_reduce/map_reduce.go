@@ -0,0 +1,228 @@
+package mapreduce
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/samber/lo"
+	lop "github.com/samber/lo/parallel"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/cli"
+	"github.com/R0Xps/exercises-in-style-go/internal/explain"
+	"github.com/R0Xps/exercises-in-style-go/internal/failure"
+	"github.com/R0Xps/exercises-in-style-go/internal/limits"
+	"github.com/R0Xps/exercises-in-style-go/internal/progress"
+	"github.com/R0Xps/exercises-in-style-go/internal/report"
+	"github.com/R0Xps/exercises-in-style-go/internal/tokens"
+)
+
+// Style is the map reduce style, which is run by its own binary and by eis
+var Style = cli.Style{Name: "map_reduce", Run: run, Explain: describe}
+
+// wordFreqEntry struct is used to store a word-frequency pair
+type wordFreqEntry struct {
+	word string
+	freq int
+}
+
+// Slice used to store stop words after reading them from a file
+var stopWords []string
+
+// The number of lines in each partition of an input file, every partition is mapped by its own worker
+const linesPerPartition = 200
+
+func run(ctx context.Context, cfg *cli.Config) error {
+	var err error
+	stopWords, err = getStopWords(cfg.StopWordsFile, cfg.Filter)
+	if err != nil {
+		return failure.Wrap(failure.StopWords, err)
+	}
+	cfg.Explain.Add("read stop words", "word", int64(len(stopWords)))
+
+	// Run a separate MapReduce job for each input file, so the report can show how often a word appears in each of them
+	result := &report.Result{Style: "map_reduce"}
+	for doc, inputPath := range cfg.InputFiles {
+		data, err := readInputFile(inputPath, cfg.Limits)
+		if err != nil {
+			return failure.Wrap(failure.Input, err)
+		}
+		cfg.Explain.Add("read", "byte", int64(len(data)))
+		cfg.Output.RecordCasing([]byte(data))
+		partitions := partition(data, linesPerPartition)
+		cfg.Explain.Add("partition", "partition", int64(len(partitions)))
+		parts := lop.Map(partitions, reportMapped(cfg.Progress, doc, len(partitions), mapUntilDone(ctx, splitWords(cfg.Filter))))
+		for _, part := range parts {
+			cfg.Explain.Add("map", "word pair", int64(len(part)))
+		}
+		var limitErr error
+		var evicted []string
+		wfMap := lo.Reduce(parts, countWords(cfg.Limits, inputPath, &evicted, &limitErr), map[string]int{})
+		if limitErr != nil {
+			return limitErr
+		}
+		// The limits apply to the words of all input files together, so the words evicted while reducing this one are removed from the ones reduced before it
+		result.Documents = report.WithoutWords(result.Documents, evicted)
+		cfg.Progress.SetUniqueWords(len(wfMap))
+		cfg.Explain.Add("reduce", "distinct word", int64(len(wfMap)))
+
+		wordFreq := sorted(wfMap)
+		cfg.Explain.Add("sort", "entry", int64(len(wordFreq)))
+
+		entries := make([]report.Entry, len(wordFreq))
+		for i, wf := range wordFreq {
+			entries[i] = report.Entry{Word: wf.word, Freq: wf.freq}
+		}
+		result.Documents = append(result.Documents, report.Document{Input: inputPath, Entries: entries})
+		if ctx.Err() != nil {
+			return report.Interrupted(cfg.Stdout, result, cfg.Output, ctx.Err())
+		}
+	}
+
+	cfg.Explain.Add("report", "document", int64(len(result.Documents)))
+	// Print the first (25 max) words and their frequencies
+	return report.Print(cfg.Stdout, result, cfg.Output)
+}
+
+// Declare the plan of the MapReduce jobs on p
+func describe(p *explain.Plan, _ *cli.Config) {
+	p.Stage("read stop words", "getStopWords(stopWordsFile, filter) -> []string, shared by every map worker")
+	p.Stage("read", "readInputFile(inputPath, limits) -> string, for each input file in turn")
+	p.Stage("partition", fmt.Sprintf("partition(data, %d) -> []string of %d lines each", linesPerPartition, linesPerPartition))
+	p.Stage("map", "lop.Map(partitions, splitWords(filter)) -> [][]wordFreqEntry, a goroutine for each partition, every word that is kept has a frequency of 1")
+	p.Stage("reduce", "lo.Reduce(parts, countWords, map[string]int{}) -> map[string]int, the parts are added one after the other")
+	p.Stage("sort", "sorted(wfMap) -> []wordFreqEntry, by frequency")
+	p.Stage("report", "report.Print(stdout, result, output)")
+	p.Note("A MapReduce job runs for each input file, one after the other, so the report has a document for each of them")
+}
+
+// Read the stop words file and return the words in it
+func getStopWords(filename string, filter *tokens.Filter) ([]string, error) {
+	rawStopWords, err := readInputFile(filename, nil)
+	if err != nil {
+		return nil, err
+	}
+	filteredStopWords := filter.Normalize([]byte(rawStopWords))
+	return strings.Fields(filteredStopWords), nil
+}
+
+// Read the input file and return its content as a string, the bytes read count towards the limits l, which are nil for files that aren't input files
+func readInputFile(filename string, l *limits.Limits) (data string, err error) {
+	file, err := os.Open(filepath.Clean(filename))
+	if err != nil {
+		return "", err
+	}
+	defer func(file *os.File) {
+		closeErr := file.Close()
+		if err == nil {
+			err = closeErr
+		}
+	}(file)
+
+	bytes, err := l.ReadAll(filename, file)
+	if err != nil {
+		return "", err
+	}
+
+	return string(bytes), nil
+}
+
+// Split the data string into smaller strings of nLines lines each (the last string might have less than nLines lines)
+func partition(data string, nLines int) []string {
+	lines := strings.Split(data, "\n")
+
+	parts := make([]string, 0, len(lines)/nLines+1)
+	for i := 0; i < len(lines); i += nLines {
+		partEnd := min(len(lines), i+nLines)
+		parts = append(parts, strings.Join(lines[i:partEnd], "\n"))
+	}
+	return parts
+}
+
+// Return the 'map' function of this MapReduce job. It takes a string, cleans it by replacing all non-token characters with spaces, and converts all uppercase letters to lowercase.
+// Then it splits the resulting string, leaving only the words. And returns a slice of all non-stop words that pass the token filter with a frequency of 1 for each of them (repeats allowed).
+// The token filter is given to the function that returns the mapper, so every map worker uses the filter of the run
+func splitWords(filter *tokens.Filter) func(string, int) []wordFreqEntry {
+	return func(data string, _ int) []wordFreqEntry {
+		cleanData := filter.Normalize([]byte(data))
+		words := strings.Fields(cleanData)
+		wordFreq := make([]wordFreqEntry, 0)
+
+		for _, word := range words {
+			if filter.Keep(word) && !isStopWord(word) {
+				wordFreq = append(wordFreq, wordFreqEntry{word, 1})
+			}
+		}
+
+		return wordFreq
+	}
+}
+
+// Return a map function that calls mapper on each part until ctx is canceled, and returns nil for the parts left after that, so the reduce step only counts the parts that were mapped
+func mapUntilDone(ctx context.Context, mapper func(string, int) []wordFreqEntry) func(string, int) []wordFreqEntry {
+	return func(data string, i int) []wordFreqEntry {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return mapper(data, i)
+	}
+}
+
+// Return a map function that calls mapper on each part, then reports to p that one more of the total parts of the document at index doc is mapped, along with the words found in it.
+// The parts are mapped concurrently, so the number of mapped parts is atomic
+func reportMapped(p *progress.Progress, doc, total int, mapper func(string, int) []wordFreqEntry) func(string, int) []wordFreqEntry {
+	var mapped atomic.Int64
+	return func(data string, i int) []wordFreqEntry {
+		wordFreq := mapper(data, i)
+		p.Checkpoint(doc, "mapping partitions", mapped.Add(1), int64(total))
+		p.AddTokens(int64(len(wordFreq)))
+		return wordFreq
+	}
+}
+
+// Check if the given word is in the stopWords slice
+func isStopWord(word string) bool {
+	return slices.Contains(stopWords, word)
+}
+
+// Return the 'reduce' function of this 'MapReduce' job for the input file at path. It combines all words and frequencies from the item slice into the agg map and returns the map, keeping as many distinct words as the limits l allow for all input files together.
+// The words the limits evict are removed from agg and added to evicted, so they can be removed from the input files reduced before.
+// The reduce function can't fail, so if the limits fail instead of making room for a word, their error is stored in err and the remaining items are skipped
+func countWords(l *limits.Limits, path string, evicted *[]string, err *error) func(map[string]int, []wordFreqEntry, int) map[string]int {
+	return func(agg map[string]int, item []wordFreqEntry, _ int) map[string]int {
+		for _, wf := range item {
+			if *err != nil {
+				break
+			}
+			var words []string
+			words, *err = l.Admit(path, wf.word, wf.freq)
+			if *err != nil {
+				break
+			}
+			for _, word := range words {
+				delete(agg, word)
+			}
+			*evicted = append(*evicted, words...)
+			agg[wf.word] += wf.freq
+		}
+		return agg
+	}
+}
+
+// Return a sorted slice of all entries in wfMap
+func sorted(wfMap map[string]int) []wordFreqEntry {
+	wordFreq := make([]wordFreqEntry, 0)
+	for k, v := range wfMap {
+		wordFreq = append(wordFreq, wordFreqEntry{k, v})
+	}
+
+	slices.SortFunc(wordFreq, func(i, j wordFreqEntry) int {
+		return j.freq - i.freq
+	})
+
+	return wordFreq
+}
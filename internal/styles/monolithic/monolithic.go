@@ -0,0 +1,225 @@
+package monolithic
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/cli"
+	"github.com/R0Xps/exercises-in-style-go/internal/failure"
+	"github.com/R0Xps/exercises-in-style-go/internal/report"
+)
+
+// Style is the monolithic style, which is run by its own binary and by eis
+var Style = cli.Style{Name: "monolithic", Run: run}
+
+// wordFreqEntry struct is used to store a word-frequency pair
+type wordFreqEntry struct {
+	word string
+	freq int
+}
+
+func run(ctx context.Context, cfg *cli.Config) (err error) {
+	// Get the token filter, the report options, and the paths of the stop words and input files from the command line
+	filter := cfg.Filter
+	output := cfg.Output
+	stopWordsPath := cfg.StopWordsFile
+	inputPaths := cfg.InputFiles
+
+	// Open the file located at stopWordsPath and read, then convert it to a slice of words
+	stopWordsFile, err := os.Open(filepath.Clean(stopWordsPath))
+	if err != nil {
+		return failure.Wrap(failure.StopWords, err)
+	}
+	// The file is only read, so an error closing it is only returned if nothing else went wrong
+	defer func(stopWordsFile *os.File) {
+		closeErr := stopWordsFile.Close()
+		if err == nil && closeErr != nil {
+			err = failure.Wrap(failure.StopWords, closeErr)
+		}
+	}(stopWordsFile)
+
+	stopWordsBytes, err := io.ReadAll(stopWordsFile)
+	if err != nil {
+		return failure.Wrap(failure.StopWords, err)
+	}
+
+	// Add a space after the string for the last word to be counted correctly, instead of adding all the word check/count logic after the loop again
+	stopWordsBytes = append(stopWordsBytes, ' ')
+
+	stopWords := make([]string, 0)
+
+	start := -1
+	// Iterate over characters in the stop words file
+	for i, c := range stopWordsBytes {
+		if start == -1 {
+			// We're currently not in a word
+			if filter.IsTokenChar(c) {
+				// This means we found the start of a word
+				start = i
+			}
+		} else {
+			if filter.IsTokenChar(c) {
+				// We're still inside a word
+				continue
+			}
+			// When we reach this point, we're at the character immediately after a word
+
+			// Copy the entire word and convert it to lowercase
+			wordBytes := stopWordsBytes[start:i]
+			for j := range wordBytes {
+				if wordBytes[j] >= 'A' && wordBytes[j] <= 'Z' {
+					wordBytes[j] += 32
+				}
+			}
+
+			word := string(wordBytes)
+			// Add the word to the stopWords slice
+			stopWords = append(stopWords, word)
+			// After we're done with a word, we want to look for the next one, so we reset the start index to -1
+			start = -1
+		}
+	}
+
+	// The result collects the words counted in every input file
+	result := &report.Result{Style: "monolithic"}
+
+	// Each input file is counted on its own, so the report can show how often a word appears in each of them
+	for doc, inputPath := range inputPaths {
+		// Open and read the file located at inputPath
+		inputFile, err := os.Open(filepath.Clean(inputPath))
+		if err != nil {
+			return failure.Wrap(failure.Input, err)
+		}
+
+		// The size of the input files is limited, so the bytes read from them are counted
+		inputBytes, err := cfg.Limits.ReadAll(inputPath, inputFile)
+		// The file is closed before checking the read error, so it isn't left open when returning
+		closeErr := inputFile.Close()
+		if err != nil {
+			return failure.Wrap(failure.Input, err)
+		}
+		if closeErr != nil {
+			return failure.Wrap(failure.Input, closeErr)
+		}
+
+		// The casing of the words is lost once they are converted to lowercase, so it is recorded first if the report needs it
+		cfg.Output.RecordCasing(inputBytes)
+
+		// Add a space after the string for the last word to be counted correctly, instead of adding all the word check/count logic after the loop again
+		inputBytes = append(inputBytes, ' ')
+
+		// This slice is used to store the words and their frequencies in descending order by frequency
+		wordFreq := make([]wordFreqEntry, 0)
+
+		// The number of tokens found since the last checkpoint
+		tokens := 0
+
+		start = -1
+		// Iterate over characters in the input file
+		for i, c := range inputBytes {
+			if start == -1 {
+				// We're currently not in a word
+				if filter.IsTokenChar(c) {
+					// This means we found the start of a word
+					start = i
+				}
+			} else {
+				if filter.IsTokenChar(c) {
+					// We're still inside a word
+					continue
+				}
+				// When we reach this point, we're at the character immediately after a word
+
+				// Copy the entire word and convert it to lowercase
+				wordBytes := inputBytes[start:i]
+				for j := range wordBytes {
+					if wordBytes[j] >= 'A' && wordBytes[j] <= 'Z' {
+						wordBytes[j] += 32
+					}
+				}
+
+				word := string(wordBytes)
+				tokens++
+
+				// Look for the word in the stopWords slice, words rejected by the token filter are skipped the same way stop words are
+				isStopWord := !filter.Keep(word)
+				for _, stopWord := range stopWords {
+					if word == stopWord {
+						isStopWord = true
+						break
+					}
+				}
+
+				if !isStopWord {
+					// If the word is not a stop word, the limits either make room for it among the words of all input files, or fail if there are too many of them
+					evicted, err := cfg.Limits.Admit(inputPath, word, 1)
+					if err != nil {
+						return err
+					}
+					// The evicted words are removed from the wordFreq slice, and from the input files counted before this one
+					for _, evictedWord := range evicted {
+						for i, wf := range wordFreq {
+							if wf.word == evictedWord {
+								wordFreq = append(wordFreq[:i], wordFreq[i+1:]...)
+								break
+							}
+						}
+					}
+					result.Documents = report.WithoutWords(result.Documents, evicted)
+
+					// Find the word in the wordFreq slice
+					idx := -1
+					for i, wf := range wordFreq {
+						if wf.word == word {
+							idx = i
+							break
+						}
+					}
+
+					if idx == -1 {
+						// The word is not in the wordFreq slice, so we append it to the slice with a frequency of 1
+						wordFreq = append(wordFreq, wordFreqEntry{word, 1})
+					} else {
+						// The word is already in the wordFreq slice, so we increment its frequency
+						wordFreq[idx].freq++
+						// Then move it up the list until it's in the correct position again
+						for idx > 0 && wordFreq[idx].freq > wordFreq[idx-1].freq {
+							wordFreq[idx], wordFreq[idx-1] = wordFreq[idx-1], wordFreq[idx]
+							idx--
+						}
+					}
+				}
+				// After we're done with a word, we want to look for the next one, so we reset the start index to -1
+				start = -1
+			}
+
+			// The end of every line is a checkpoint, where the progress is reported and the run can stop if it was canceled
+			if c == '\n' {
+				cfg.Progress.Checkpoint(doc, "scanning", int64(i), int64(len(inputBytes)))
+				cfg.Progress.AddTokens(int64(tokens))
+				cfg.Progress.SetUniqueWords(len(wordFreq))
+				tokens = 0
+				if ctx.Err() != nil {
+					break
+				}
+			}
+		}
+
+		// Copy the list into report entries, which are all the words counted in this input file
+		entries := make([]report.Entry, len(wordFreq))
+		for i, wf := range wordFreq {
+			entries[i] = report.Entry{Word: wf.word, Freq: wf.freq}
+		}
+		result.Documents = append(result.Documents, report.Document{Input: inputPath, Entries: entries})
+
+		// The words counted in the file before the run was canceled are still added to the result
+		if ctx.Err() != nil {
+			return report.Interrupted(cfg.Stdout, result, output, ctx.Err())
+		}
+	}
+
+	// Print the words with the highest frequencies in all input files (25 words at most)
+	return report.Print(cfg.Stdout, result, output)
+}
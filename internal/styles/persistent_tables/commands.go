@@ -0,0 +1,276 @@
+package persistenttables
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/cli"
+	"github.com/R0Xps/exercises-in-style-go/internal/failure"
+	"github.com/R0Xps/exercises-in-style-go/internal/report"
+)
+
+// The subcommands of the style, which use a database created by the style. They read the stop words from the database, so they don't take a stop words file
+var (
+	addCommand = cli.Style{
+		Name:            "add",
+		Summary:         "add the input files to the documents of an existing database",
+		Database:        true,
+		StoredStopWords: true,
+		Run:             add,
+		Flags:           noFlags,
+	}
+	listCommand = cli.Style{
+		Name:            "list",
+		Summary:         "list the documents stored in the database",
+		Database:        true,
+		StoredStopWords: true,
+		StoredInputs:    true,
+		Run:             list,
+		Flags:           noFlags,
+	}
+	rankCommand = cli.Style{
+		Name:            "rank",
+		Summary:         "rank the words of all the stored documents, or of the ones chosen with --documents",
+		Database:        true,
+		StoredStopWords: true,
+		StoredInputs:    true,
+		Run:             rank,
+		Flags:           registerRankFlags,
+	}
+)
+
+// The add and list commands have no options of their own. Like rank, they don't take --watch, --follow and --explain, which only the styles have, and list doesn't take the options of the token filter and the limits on the input either, since it reads the stored documents
+func noFlags(*flag.FlagSet) any { return nil }
+
+// The options that only rank has
+type rankOptions struct {
+	// The documents chosen with --documents, by id or name. Every document is ranked if it's empty
+	documents []string
+}
+
+// Register the --documents option, and return the options it sets
+func registerRankFlags(fs *flag.FlagSet) any {
+	opts := &rankOptions{}
+	fs.Func("documents", "rank only the comma-separated `list` of documents, given by their id or their name as shown by list (default all of them)", func(value string) error {
+		opts.documents = nil
+		for _, document := range strings.Split(value, ",") {
+			document = strings.TrimSpace(document)
+			if document == "" {
+				return errors.New("empty document in the list")
+			}
+			opts.documents = append(opts.documents, document)
+		}
+		return nil
+	})
+	return opts
+}
+
+// Return an error if the database file doesn't exist, the subcommands only use an existing database, and connecting to a missing one would create it
+func checkDatabase(dbFile string) error {
+	exists, err := fileExists(dbFile)
+	if err != nil {
+		return failure.Wrap(failure.Storage, err)
+	}
+	if !exists {
+		return failure.Wrap(failure.Storage, fmt.Errorf("%s doesn't exist, create it with 'persistent_tables <stop_words_file> <input_file>... %s'", dbFile, dbFile))
+	}
+	return nil
+}
+
+// Insert the input files into an existing database as new documents, each of them in a transaction of its own, and print the id and the number of words stored of each of them.
+// The words go through the token filter and the stop words stored in the database. Input files that are already stored are refused before anything is inserted, so a document isn't counted twice,
+// and the columns of the token counts are added to a database created by an older version of the style first
+func add(ctx context.Context, cfg *cli.Config) (err error) {
+	err = checkDatabase(cfg.DatabaseFile)
+	if err != nil {
+		return err
+	}
+	db, err := openDatabase(cfg.DatabaseFile)
+	if err != nil {
+		return err
+	}
+	defer closeDatabase(db, &err)
+
+	var documents []storedDocument
+	err = inTransaction(ctx, db, func(tx *sql.Tx) error {
+		documents, err = storedDocuments(tx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	for i, inputFile := range cfg.InputFiles {
+		for _, d := range documents {
+			if filepath.Clean(d.name) == filepath.Clean(inputFile) {
+				return failure.Wrap(failure.Input, fmt.Errorf("%s is already stored in %s as document %d", inputFile, cfg.DatabaseFile, d.id))
+			}
+		}
+		if slices.ContainsFunc(cfg.InputFiles[:i], func(previous string) bool { return filepath.Clean(previous) == filepath.Clean(inputFile) }) {
+			return failure.Wrap(failure.Usage, fmt.Errorf("%s is given more than once", inputFile))
+		}
+	}
+
+	// Databases created by older versions of the style don't have the columns the token counts are stored in, or the table of the casing
+	err = inTransaction(ctx, db, upgradeTables)
+	if err != nil {
+		return err
+	}
+
+	var docIds []int
+	for i, inputFile := range cfg.InputFiles {
+		var inserted insertedRows
+		err = inTransaction(ctx, db, func(tx *sql.Tx) error {
+			inserted, err = insertData(ctx, tx, i, inputFile, docIds, cfg.Filter, cfg.Limits, cfg.Progress)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		docIds = append(docIds, inserted.docId)
+		_, err = fmt.Fprintf(cfg.Stdout, "added %s as document %d, %d words stored\n", inputFile, inserted.docId, int64(inserted.words)-inserted.evicted)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Print a table of the documents stored in the database: their id, their name, their token counts, and the number of words stored for them and how many of them are distinct
+func list(_ context.Context, cfg *cli.Config) (err error) {
+	if cfg.Output.Format != "plain" {
+		return failure.Wrap(failure.Usage, fmt.Errorf("list only prints a table, not the %q format", cfg.Output.Format))
+	}
+	err = checkDatabase(cfg.DatabaseFile)
+	if err != nil {
+		return err
+	}
+	db, err := openDatabase(cfg.DatabaseFile)
+	if err != nil {
+		return err
+	}
+	defer closeDatabase(db, &err)
+
+	rows, err := db.Query(sqlSelectDocumentSummaries)
+	if err != nil {
+		rows, err = db.Query(sqlSelectDocumentSummariesOld)
+	}
+	if err != nil {
+		return failure.Wrap(failure.Storage, fmt.Errorf("retrieving documents from database: %w", err))
+	}
+	defer func() { _ = rows.Close() }()
+
+	tw := tabwriter.NewWriter(cfg.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "id\tdocument\ttokens\tkept\twords\tdistinct words\t")
+	documents := 0
+	for rows.Next() {
+		var id, words, distinct int64
+		var name string
+		var tokens, kept sql.NullInt64
+		err = rows.Scan(&id, &name, &tokens, &kept, &words, &distinct)
+		if err != nil {
+			return failure.Wrap(failure.Storage, fmt.Errorf("retrieving documents from database: %w", err))
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t\n", id, name, count(tokens), count(kept), humanize.Comma(words), humanize.Comma(distinct))
+		documents++
+	}
+	if err = rows.Err(); err != nil {
+		return failure.Wrap(failure.Storage, fmt.Errorf("retrieving documents from database: %w", err))
+	}
+	if documents == 0 {
+		_, err = fmt.Fprintf(cfg.Stdout, "no documents are stored in %s\n", cfg.DatabaseFile)
+		return err
+	}
+	return tw.Flush()
+}
+
+// Return the token count of a document, or "-" if it isn't stored
+func count(n sql.NullInt64) string {
+	if !n.Valid {
+		return "-"
+	}
+	return humanize.Comma(n.Int64)
+}
+
+// Print the words with the highest frequencies in the documents chosen with --documents, or in all of them, the same way as the style
+func rank(_ context.Context, cfg *cli.Config) (err error) {
+	err = checkDatabase(cfg.DatabaseFile)
+	if err != nil {
+		return err
+	}
+	db, err := openDatabase(cfg.DatabaseFile)
+	if err != nil {
+		return err
+	}
+	defer closeDatabase(db, &err)
+
+	var chosen []string
+	if opts, ok := cfg.Options.(*rankOptions); ok {
+		chosen = opts.documents
+	}
+	result, err := readResult(db, chosen, cfg.Output.NeedsCasing(), nil)
+	if err != nil {
+		return err
+	}
+	return report.Print(cfg.Stdout, result, cfg.Output)
+}
+
+// Return the documents chosen by their id or their name, in the order they were inserted, or all of them if chosen is empty.
+// A name is either the name of the document, which is the path of the input file it was inserted from, or the base name of that path if only one document has it
+func chooseDocuments(documents []storedDocument, chosen []string) ([]storedDocument, error) {
+	if len(chosen) == 0 {
+		return documents, nil
+	}
+	var ids []int
+	for _, c := range chosen {
+		matches := matchDocuments(documents, c)
+		if len(matches) == 0 {
+			return nil, failure.Wrap(failure.Usage, fmt.Errorf("no document %q is stored, run 'persistent_tables list' to list them", c))
+		}
+		if len(matches) > 1 {
+			ids := make([]string, len(matches))
+			for i, d := range matches {
+				ids[i] = strconv.Itoa(d.id)
+			}
+			return nil, failure.Wrap(failure.Usage, fmt.Errorf("%q is the name of documents %s, choose one of them by its id", c, strings.Join(ids, ", ")))
+		}
+		ids = append(ids, matches[0].id)
+	}
+
+	var result []storedDocument
+	for _, d := range documents {
+		if slices.Contains(ids, d.id) {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+// Return the documents matched by the given id or name. The id and the full name are matched first, the base name only if nothing else matches
+func matchDocuments(documents []storedDocument, c string) []storedDocument {
+	var matches []storedDocument
+	id, idErr := strconv.Atoi(c)
+	for _, d := range documents {
+		if (idErr == nil && d.id == id) || filepath.Clean(d.name) == filepath.Clean(c) {
+			matches = append(matches, d)
+		}
+	}
+	if len(matches) > 0 {
+		return matches
+	}
+	for _, d := range documents {
+		if filepath.Base(d.name) == c {
+			matches = append(matches, d)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,319 @@
+package persistenttables
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/cli"
+	"github.com/R0Xps/exercises-in-style-go/internal/failure"
+	"github.com/R0Xps/exercises-in-style-go/internal/report"
+	"github.com/R0Xps/exercises-in-style-go/internal/tokens"
+)
+
+// The example input files, relative to the package
+var (
+	input1 = filepath.Join("..", "..", "..", "examples", "input", "input1.txt")
+	input2 = filepath.Join("..", "..", "..", "examples", "input", "input2.txt")
+)
+
+// Return a new config of a command on the database file dbFile with the given input files, which writes to the returned buffer
+func newTestConfig(dbFile string, inputFiles ...string) (*cli.Config, *bytes.Buffer) {
+	filter := tokens.NewFilter()
+	var out bytes.Buffer
+	cfg := &cli.Config{
+		Filter:       filter,
+		Output:       report.NewOptions(filter),
+		InputFiles:   inputFiles,
+		DatabaseFile: dbFile,
+		Stdout:       &out,
+	}
+	return cfg, &out
+}
+
+// Create a database the way the style did before the token counts were stored, with a single document holding the given words
+func createOldDatabase(t *testing.T, name string, words ...string) string {
+	t.Helper()
+	dbFile := filepath.Join(t.TempDir(), "old.db")
+	db, err := sql.Open("sqlite", dbFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Close() }()
+
+	statements := []string{
+		"CREATE TABLE documents (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT)",
+		sqlCreateWords,
+		sqlCreateStopWords,
+		"INSERT INTO stop_words (word) VALUES ('the')",
+		"INSERT INTO documents (name) VALUES ('" + name + "')",
+	}
+	for _, s := range statements {
+		if _, err := db.Exec(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, word := range words {
+		if _, err := db.Exec(sqlInsertWord, i+1, 1, word); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dbFile
+}
+
+// Rank the documents of the database file in the json format, and return the fields of the document it writes
+func rankJSON(t *testing.T, dbFile string) map[string]any {
+	t.Helper()
+	cfg, out := newTestConfig(dbFile)
+	cfg.Output.Format = "json"
+	err := rank(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]any
+	err = json.Unmarshal(out.Bytes(), &doc)
+	if err != nil {
+		t.Fatalf("%v\n%s", err, out.String())
+	}
+	return doc
+}
+
+func TestOldDatabase(t *testing.T) {
+	dbFile := createOldDatabase(t, "old.txt", "darcy", "darcy", "jane")
+
+	// The old document has no token counts, so only the counted tokens are known
+	doc := rankJSON(t, dbFile)
+	if _, ok := doc["total_tokens"]; ok || doc["counted_tokens"] != 3.0 {
+		t.Errorf("unexpected counts for an old database: %v", doc)
+	}
+	// The summaries of an old database are read without the columns it doesn't have
+	cfg, out := newTestConfig(dbFile)
+	err := list(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "old.txt   -       -     3      2") {
+		t.Errorf("unexpected list of an old database:\n%s", out.String())
+	}
+
+	// add adds the columns of the token counts, and stores them for the new document
+	cfg, out = newTestConfig(dbFile, input2)
+	err = add(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "as document 2") {
+		t.Errorf("unexpected output of add:\n%s", out.String())
+	}
+	cfg, out = newTestConfig(dbFile)
+	err = list(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[1], "-") || !strings.Contains(lines[2], "15") {
+		t.Errorf("unexpected list after add:\n%s", out.String())
+	}
+
+	// The counts of the old document are still unknown, so the counts of a ranking with it are too
+	doc = rankJSON(t, dbFile)
+	if _, ok := doc["stop_words_removed"]; ok || doc["counted_tokens"] != 18.0 {
+		t.Errorf("unexpected counts after add: %v", doc)
+	}
+}
+
+// Create a database with the example stop words and the first example input, and return its path
+func createTestDatabase(t *testing.T) string {
+	t.Helper()
+	cfg, _ := newTestConfig(filepath.Join(t.TempDir(), "test.db"), input1)
+	cfg.StopWordsFile = filepath.Join("..", "..", "..", "examples", "stop_words.txt")
+	err := run(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg.DatabaseFile
+}
+
+func TestAdd(t *testing.T) {
+	dbFile := createTestDatabase(t)
+
+	// Input files that are already stored, or given more than once, are refused before anything is inserted
+	tests := []struct {
+		inputFiles []string
+		kind       failure.Kind
+		wantErr    string
+	}{
+		{[]string{input2, input1}, failure.Input, "is already stored in " + dbFile + " as document 1"},
+		{[]string{filepath.Join("..", "..", "..", "examples", "input", ".", "input1.txt")}, failure.Input, "as document 1"},
+		{[]string{input2, filepath.Join("..", "..", "..", "examples", "input", "..", "input", "input2.txt")}, failure.Usage, "is given more than once"},
+	}
+	for _, tt := range tests {
+		cfg, out := newTestConfig(dbFile, tt.inputFiles...)
+		err := add(context.Background(), cfg)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) || failure.KindOf(err) != tt.kind {
+			t.Errorf("add %v: got the error %v, want one with %q of the %s kind", tt.inputFiles, err, tt.wantErr, tt.kind)
+		}
+		if out.Len() != 0 {
+			t.Errorf("add %v: unexpected output:\n%s", tt.inputFiles, out.String())
+		}
+	}
+
+	cfg, out := newTestConfig(dbFile, input2)
+	err := add(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "added " + input2 + " as document 2, 4 words stored\n"; out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+}
+
+func TestChooseDocuments(t *testing.T) {
+	documents := []storedDocument{{1, "a/one.txt"}, {2, "b/one.txt"}, {3, "two.txt"}, {4, "c/three.txt"}}
+	tests := []struct {
+		chosen  []string
+		want    []int
+		wantErr string
+	}{
+		{nil, []int{1, 2, 3, 4}, ""},
+		// By id, by name and by base name, in the order the documents were inserted
+		{[]string{"2"}, []int{2}, ""},
+		{[]string{"a/./one.txt"}, []int{1}, ""},
+		{[]string{"three.txt", "two.txt", "1"}, []int{1, 3, 4}, ""},
+		// A base name that more than one document has is ambiguous, unless it is also the name of a document
+		{[]string{"one.txt"}, nil, `"one.txt" is the name of documents 1, 2, choose one of them by its id`},
+		{[]string{"5"}, nil, `no document "5" is stored`},
+		{[]string{"two"}, nil, `no document "two" is stored`},
+	}
+	for _, tt := range tests {
+		got, err := chooseDocuments(documents, tt.chosen)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) || failure.KindOf(err) != failure.Usage {
+				t.Errorf("%q: got the error %v, want %q", tt.chosen, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tt.chosen, err)
+			continue
+		}
+		var ids []int
+		for _, d := range got {
+			ids = append(ids, d.id)
+		}
+		if !slices.Equal(ids, tt.want) {
+			t.Errorf("%q: got the documents %v, want %v", tt.chosen, ids, tt.want)
+		}
+	}
+
+	// The full name of a document is chosen over the base name of another one
+	documents = append(documents, storedDocument{5, "one.txt"})
+	got, err := chooseDocuments(documents, []string{"one.txt"})
+	if err != nil || len(got) != 1 || got[0].id != 5 {
+		t.Errorf("got the documents %v and the error %v, want document 5", got, err)
+	}
+}
+
+func TestRankDocuments(t *testing.T) {
+	dbFile := createTestDatabase(t)
+	cfg, _ := newTestConfig(dbFile, input2)
+	err := add(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every parse gets its own options, so the documents chosen by a run aren't ranked by the next one
+	for _, args := range [][]string{{"--documents", "input2.txt"}, nil} {
+		fs := flag.NewFlagSet("rank", flag.ContinueOnError)
+		cfg, out := newTestConfig(dbFile)
+		cfg.Options = registerRankFlags(fs)
+		err = fs.Parse(args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg.Output.Format = "json"
+		err = rank(context.Background(), cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var doc struct {
+			Inputs []string `json:"inputs"`
+		}
+		err = json.Unmarshal(out.Bytes(), &doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{input1, input2}
+		if args != nil {
+			want = []string{input2}
+		}
+		if !slices.Equal(doc.Inputs, want) {
+			t.Errorf("rank %v: got the inputs %v, want %v", args, doc.Inputs, want)
+		}
+	}
+
+	fs := flag.NewFlagSet("rank", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	registerRankFlags(fs)
+	if err := fs.Parse([]string{"--documents", "1,,2"}); err == nil {
+		t.Error("expected an error for an empty document in the list")
+	}
+}
+
+func TestRankCasing(t *testing.T) {
+	inputFile := filepath.Join(t.TempDir(), "letters.txt")
+	err := os.WriteFile(inputFile, []byte("Letters came. Then Darcy wrote the letters, and Jane read the letters to Darcy\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, _ := newTestConfig(filepath.Join(t.TempDir(), "test.db"), inputFile)
+	cfg.StopWordsFile = filepath.Join("..", "..", "..", "examples", "stop_words.txt")
+	err = run(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The casing is stored in the database, so the words are still ranked by it once the input file is gone
+	err = os.Remove(inputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		onlyProper bool
+		want       string
+	}{
+		{true, "darcy - 2\njane - 1\n"},
+		{false, "letters - 3\ncame - 1\nread - 1\nwrote - 1\n"},
+	}
+	for _, tt := range tests {
+		cfg, out := newTestConfig(cfg.DatabaseFile)
+		cfg.Output.OnlyProper, cfg.Output.ExcludeProper = tt.onlyProper, !tt.onlyProper
+		err = rank(context.Background(), cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("only-proper %v: got\n%s\nwant\n%s", tt.onlyProper, out.String(), tt.want)
+		}
+	}
+
+	// The documents of an older version have no casing, so they can't be ranked by it
+	dbFile := createOldDatabase(t, "old.txt", "darcy", "darcy", "jane")
+	cfg, out := newTestConfig(dbFile)
+	cfg.Output.OnlyProper = true
+	err = rank(context.Background(), cfg)
+	if err == nil || failure.KindOf(err) != failure.Usage || !strings.Contains(err.Error(), "the casing of documents 1 isn't stored") {
+		t.Errorf("got the error %v, want one about the casing of document 1", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+}
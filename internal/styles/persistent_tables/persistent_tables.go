@@ -0,0 +1,736 @@
+package persistenttables
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/casing"
+	"github.com/R0Xps/exercises-in-style-go/internal/cli"
+	"github.com/R0Xps/exercises-in-style-go/internal/explain"
+	"github.com/R0Xps/exercises-in-style-go/internal/failure"
+	"github.com/R0Xps/exercises-in-style-go/internal/limits"
+	"github.com/R0Xps/exercises-in-style-go/internal/progress"
+	"github.com/R0Xps/exercises-in-style-go/internal/report"
+	"github.com/R0Xps/exercises-in-style-go/internal/tokens"
+)
+
+// Style is the persistent tables style, which is run by its own binary and by eis. Its subcommands add documents to an existing database, list them, and rank some of them
+var Style = cli.Style{Name: "persistent_tables", Database: true, Run: run, Explain: describe, Commands: []cli.Style{addCommand, listCommand, rankCommand}}
+
+// The SQL statements of the style, which are also shown by its plan
+const (
+	sqlCreateDocuments    = "CREATE TABLE documents (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT, tokens INTEGER, kept INTEGER, evicted INTEGER)"
+	sqlCreateWords        = "CREATE TABLE words (id INTEGER PRIMARY KEY, doc_id INTEGER, word TEXT, FOREIGN KEY(doc_id) REFERENCES documents(id))"
+	sqlCreateStopWords    = "CREATE TABLE stop_words (word TEXT PRIMARY KEY)"
+	sqlCreateCasing       = "CREATE TABLE IF NOT EXISTS casing (doc_id INTEGER, word TEXT, lower INTEGER, title INTEGER, upper INTEGER, mixed INTEGER, mid_sentence INTEGER, capitalized_mid_sentence INTEGER, PRIMARY KEY(doc_id, word), FOREIGN KEY(doc_id) REFERENCES documents(id))"
+	sqlInsertStopWord     = "INSERT INTO stop_words (word) VALUES (?)"
+	sqlInsertDocument     = "INSERT INTO documents (name) VALUES (?)"
+	sqlSelectDocumentId   = "SELECT MAX(id) FROM documents WHERE name=?"
+	sqlSelectStopWords    = "SELECT word FROM stop_words"
+	sqlSelectLastWordId   = "SELECT COALESCE(MAX(id), 0) FROM words"
+	sqlInsertWord         = "INSERT INTO words (id, doc_id, word) values (?, ?, ?)"
+	sqlCreateKeptWords    = "CREATE TEMP TABLE kept_words (word TEXT PRIMARY KEY, start INTEGER)"
+	sqlInsertKeptWord     = "INSERT INTO kept_words (word, start) VALUES (?, ?)"
+	sqlDeleteEvictedWords = "DELETE FROM words WHERE doc_id=? AND NOT EXISTS (SELECT 1 FROM kept_words k WHERE k.word = words.word AND words.id >= k.start)"
+	sqlDeleteStoredWord   = "DELETE FROM words WHERE doc_id=? AND word=?"
+	sqlDeleteStoredCasing = "DELETE FROM casing WHERE doc_id=? AND word=?"
+	sqlAddEvictedTokens   = "UPDATE documents SET evicted=evicted+? WHERE id=?"
+	sqlDropKeptWords      = "DROP TABLE kept_words"
+	sqlUpdateTokenCounts  = "UPDATE documents SET tokens=?, kept=?, evicted=? WHERE id=?"
+	sqlInsertCasing       = "INSERT INTO casing (doc_id, word, lower, title, upper, mixed, mid_sentence, capitalized_mid_sentence) VALUES (?, ?, ?, ?, ?, ?, ?, ?)"
+	sqlSelectDocuments    = "SELECT id, name FROM documents ORDER BY id"
+	sqlCreateChosen       = "CREATE TEMP TABLE chosen_documents (id INTEGER PRIMARY KEY)"
+	sqlInsertChosen       = "INSERT INTO chosen_documents (id) VALUES (?)"
+	sqlSelectWordFreqs    = "SELECT doc_id, word, COUNT(*) AS freq FROM words WHERE doc_id IN (SELECT id FROM chosen_documents) GROUP BY doc_id, word ORDER BY doc_id, freq DESC"
+	sqlSelectUncased      = "SELECT id FROM chosen_documents c WHERE EXISTS (SELECT 1 FROM words w WHERE w.doc_id = c.id) AND NOT EXISTS (SELECT 1 FROM casing k WHERE k.doc_id = c.id) ORDER BY id"
+	sqlSelectCasing       = "SELECT word, SUM(lower), SUM(title), SUM(upper), SUM(mixed), SUM(mid_sentence), SUM(capitalized_mid_sentence) FROM casing WHERE doc_id IN (SELECT id FROM chosen_documents) GROUP BY word"
+	sqlSelectTokenCounts  = "SELECT COALESCE(SUM(tokens), 0), COALESCE(SUM(kept), 0), COALESCE(SUM(evicted), 0), COUNT(*) - COUNT(tokens + kept) FROM documents WHERE id IN (SELECT id FROM chosen_documents)"
+	// The columns of the documents table, databases created before the token counts were stored don't have all of them
+	sqlSelectDocumentColumns = "SELECT name FROM pragma_table_info('documents')"
+	sqlAddDocumentColumn     = "ALTER TABLE documents ADD COLUMN %s INTEGER"
+	// The summaries of the documents printed by list, databases created before the token counts were stored don't have them
+	sqlSelectDocumentSummaries    = "SELECT d.id, d.name, d.tokens, d.kept, COUNT(w.id), COUNT(DISTINCT w.word) FROM documents d LEFT JOIN words w ON w.doc_id = d.id GROUP BY d.id ORDER BY d.id"
+	sqlSelectDocumentSummariesOld = "SELECT d.id, d.name, NULL, NULL, COUNT(w.id), COUNT(DISTINCT w.word) FROM documents d LEFT JOIN words w ON w.doc_id = d.id GROUP BY d.id ORDER BY d.id"
+)
+
+// wordFreqEntry struct is used to store a word-frequency pair
+type wordFreqEntry struct {
+	word string
+	freq int
+}
+
+func run(ctx context.Context, cfg *cli.Config) (err error) {
+	// The token filter is only used when the data is inserted, an existing database keeps the words it was created with
+	filter := cfg.Filter
+	output := cfg.Output
+	stopWordsFile := cfg.StopWordsFile
+	inputFiles := cfg.InputFiles
+	dbFile := cfg.DatabaseFile
+
+	// Check if the database file exists before connecting to it, since connecting creates it
+	exists, err := fileExists(dbFile)
+	if err != nil {
+		return failure.Wrap(failure.Storage, err)
+	}
+
+	db, err := openDatabase(dbFile)
+	if err != nil {
+		return err
+	}
+	defer closeDatabase(db, &err)
+
+	// If the database file doesn't exist, create the tables and insert the data into them (automatically creates the database file).
+	// A run interrupted after some documents were stored keeps them, and goes on to read them so they can be printed as a partial result
+	var interrupted error
+	if !exists {
+		stored, err := createDatabase(ctx, db, stopWordsFile, inputFiles, filter, cfg.Limits, cfg.Progress, cfg.Explain)
+		if ctx.Err() != nil && errors.Is(err, ctx.Err()) && stored > 0 {
+			interrupted = err
+		} else if err != nil {
+			// The database file didn't exist before, so it is removed instead of being left empty or incomplete for the next run to use
+			_ = db.Close()
+			_ = os.Remove(dbFile)
+			if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+				result := &report.Result{Style: "persistent_tables"}
+				for _, inputFile := range inputFiles {
+					result.Documents = append(result.Documents, report.Document{Input: inputFile, Entries: make([]report.Entry, 0)})
+				}
+				return report.Interrupted(cfg.Stdout, result, output, err)
+			}
+			return err
+		}
+	}
+
+	// Every document stored in the database is a separate document in the result
+	result, err := readResult(db, nil, cfg.Output.NeedsCasing(), cfg.Explain)
+	if err != nil {
+		return err
+	}
+	if exists {
+		warnUnstored(dbFile, inputFiles, result)
+	}
+
+	if interrupted != nil {
+		return report.Interrupted(cfg.Stdout, result, output, interrupted)
+	}
+	// Print the words with the highest frequencies in all documents
+	return report.Print(cfg.Stdout, result, output)
+}
+
+// Open the database file, which is created if it doesn't exist
+func openDatabase(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, failure.Wrap(failure.Storage, err)
+	}
+	return db, nil
+}
+
+// Close the database, and set *err to the error closing it unless there already is one. It is deferred by the functions that open the database
+func closeDatabase(db *sql.DB, err *error) {
+	closeErr := db.Close()
+	if *err == nil && closeErr != nil {
+		*err = failure.Wrap(failure.Storage, closeErr)
+	}
+}
+
+// Read the words and their frequencies in the documents stored in the database, and return them as a result with a document for each of them.
+// Only the documents chosen by their id or their name are read, or all of them if chosen is empty. They go into a temporary table, so the queries only count their words without a statement for every document.
+// The casing of their words is read too if withCasing is set, it is stored with the words since the input files may have changed or be gone by now.
+// The queries run in a transaction that is rolled back, which drops the temporary table, and they don't take the context of the run, so an interrupted run still reads the documents it stored
+func readResult(db *sql.DB, chosen []string, withCasing bool, plan *explain.Plan) (*report.Result, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, failure.Wrap(failure.Storage, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	documents, err := storedDocuments(tx)
+	if err != nil {
+		return nil, err
+	}
+	plan.Add("read documents", "row", int64(len(documents)))
+	documents, err = chooseDocuments(documents, chosen)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(sqlCreateChosen)
+	if err != nil {
+		return nil, failure.Wrap(failure.Storage, fmt.Errorf("choosing documents: %w", err))
+	}
+	result := &report.Result{Style: "persistent_tables"}
+	docIndex := make(map[int]int)
+	for _, d := range documents {
+		_, err = tx.Exec(sqlInsertChosen, d.id)
+		if err != nil {
+			return nil, failure.Wrap(failure.Storage, fmt.Errorf("choosing documents: %w", err))
+		}
+		plan.Add("choose documents", "row", 1)
+		docIndex[d.id] = len(result.Documents)
+		result.Documents = append(result.Documents, report.Document{Input: d.name, Entries: make([]report.Entry, 0)})
+	}
+
+	// Get all words and their frequencies in each document, the report stage needs all of them to merge aliases before picking the top 25 words
+	rows, err := tx.Query(sqlSelectWordFreqs)
+	if err != nil {
+		return nil, failure.Wrap(failure.Storage, fmt.Errorf("retrieving words and their frequencies from database: %w", err))
+	}
+	for rows.Next() {
+		var docId int
+		wordFreqEntry := wordFreqEntry{}
+		err = rows.Scan(&docId, &wordFreqEntry.word, &wordFreqEntry.freq)
+		if err != nil {
+			_ = rows.Close()
+			return nil, failure.Wrap(failure.Storage, fmt.Errorf("retrieving words and their frequencies from database: %w", err))
+		}
+		plan.Add("count words", "row", 1)
+		doc := &result.Documents[docIndex[docId]]
+		doc.Entries = append(doc.Entries, report.Entry{Word: wordFreqEntry.word, Freq: wordFreqEntry.freq})
+	}
+	if err = rows.Err(); err != nil {
+		return nil, failure.Wrap(failure.Storage, fmt.Errorf("retrieving words and their frequencies from database: %w", err))
+	}
+
+	if withCasing {
+		result.Casing, err = readCasing(tx)
+		if err != nil {
+			return nil, err
+		}
+		plan.Add("read casing", "row", int64(len(result.Casing)))
+	}
+
+	// The token counts are stored with the documents, since the words don't go through the token filter when the database already exists.
+	// Databases created before these columns were added don't have them, and documents stored before add added the columns to such a database have no counts, in which case the counts are unknown
+	columns, err := documentColumns(tx)
+	if err != nil {
+		return nil, err
+	}
+	if !hasTokenCounts(columns) {
+		result.StatsUnknown = true
+		return result, nil
+	}
+	stats := report.Stats{}
+	var uncounted int
+	err = tx.QueryRow(sqlSelectTokenCounts).Scan(&stats.Tokens, &stats.Kept, &stats.Evicted, &uncounted)
+	if err != nil {
+		return nil, failure.Wrap(failure.Storage, fmt.Errorf("retrieving token counts from database: %w", err))
+	}
+	plan.Add("sum token counts", "row", 1)
+	result.Stats = &stats
+	result.StatsUnknown = uncounted > 0
+	return result, nil
+}
+
+// Return the casing of the words of the chosen documents, added up over all of them.
+// Databases created by older versions of the style don't store the casing, so the documents without it are refused instead of ranking their words as if they were never capitalized
+func readCasing(tx *sql.Tx) (map[string]*casing.Stats, error) {
+	// A database created before the casing was stored doesn't have its table, which is the same as a table without the casing of any document
+	_, err := tx.Exec(sqlCreateCasing)
+	if err != nil {
+		return nil, failure.Wrap(failure.Storage, fmt.Errorf("retrieving casing from database: %w", err))
+	}
+	rows, err := tx.Query(sqlSelectUncased)
+	if err != nil {
+		return nil, failure.Wrap(failure.Storage, fmt.Errorf("retrieving casing from database: %w", err))
+	}
+	var uncased []string
+	for rows.Next() {
+		var id int
+		err = rows.Scan(&id)
+		if err != nil {
+			_ = rows.Close()
+			return nil, failure.Wrap(failure.Storage, fmt.Errorf("retrieving casing from database: %w", err))
+		}
+		uncased = append(uncased, strconv.Itoa(id))
+	}
+	if err = rows.Err(); err != nil {
+		return nil, failure.Wrap(failure.Storage, fmt.Errorf("retrieving casing from database: %w", err))
+	}
+	if len(uncased) > 0 {
+		return nil, failure.Wrap(failure.Usage, fmt.Errorf("the casing of documents %s isn't stored, since they were stored by an older version of persistent_tables, choose the other documents with --documents or store them again", strings.Join(uncased, ", ")))
+	}
+
+	rows, err = tx.Query(sqlSelectCasing)
+	if err != nil {
+		return nil, failure.Wrap(failure.Storage, fmt.Errorf("retrieving casing from database: %w", err))
+	}
+	defer func() { _ = rows.Close() }()
+	stats := make(map[string]*casing.Stats)
+	for rows.Next() {
+		var word string
+		s := &casing.Stats{}
+		err = rows.Scan(&word, &s.Lower, &s.Title, &s.Upper, &s.Mixed, &s.MidSentence, &s.CapitalizedMidSentence)
+		if err != nil {
+			return nil, failure.Wrap(failure.Storage, fmt.Errorf("retrieving casing from database: %w", err))
+		}
+		stats[word] = s
+	}
+	if err = rows.Err(); err != nil {
+		return nil, failure.Wrap(failure.Storage, fmt.Errorf("retrieving casing from database: %w", err))
+	}
+	return stats, nil
+}
+
+// The columns of the documents table that hold the token counts of a document
+var tokenCountColumns = []string{"tokens", "kept", "evicted"}
+
+// Return the names of the columns of the documents table
+func documentColumns(tx *sql.Tx) ([]string, error) {
+	rows, err := tx.Query(sqlSelectDocumentColumns)
+	if err != nil {
+		return nil, failure.Wrap(failure.Storage, fmt.Errorf("retrieving the columns of the documents table: %w", err))
+	}
+	defer func() { _ = rows.Close() }()
+
+	var columns []string
+	for rows.Next() {
+		var column string
+		err = rows.Scan(&column)
+		if err != nil {
+			return nil, failure.Wrap(failure.Storage, fmt.Errorf("retrieving the columns of the documents table: %w", err))
+		}
+		columns = append(columns, column)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, failure.Wrap(failure.Storage, fmt.Errorf("retrieving the columns of the documents table: %w", err))
+	}
+	return columns, nil
+}
+
+// Add the tables and the columns of the token counts that a database created by an older version of the style doesn't have, so documents can be added to it with their counts and their casing.
+// The documents stored before have no counts in these columns and no casing, so the counts of a result with any of them are still unknown, and they can't be ranked by their casing
+func upgradeTables(tx *sql.Tx) error {
+	_, err := tx.Exec(sqlCreateCasing)
+	if err != nil {
+		return failure.Wrap(failure.Storage, fmt.Errorf("creating casing table: %w", err))
+	}
+	columns, err := documentColumns(tx)
+	if err != nil {
+		return err
+	}
+	for _, c := range tokenCountColumns {
+		if slices.Contains(columns, c) {
+			continue
+		}
+		_, err = tx.Exec(fmt.Sprintf(sqlAddDocumentColumn, c))
+		if err != nil {
+			return failure.Wrap(failure.Storage, fmt.Errorf("adding the %s column to the documents table: %w", c, err))
+		}
+	}
+	return nil
+}
+
+// Check if the documents table has every column of the token counts
+func hasTokenCounts(columns []string) bool {
+	for _, c := range tokenCountColumns {
+		if !slices.Contains(columns, c) {
+			return false
+		}
+	}
+	return true
+}
+
+// storedDocument is a row of the documents table
+type storedDocument struct {
+	id   int
+	name string
+}
+
+// Return every document stored in the database, in the order they were inserted
+func storedDocuments(tx *sql.Tx) ([]storedDocument, error) {
+	rows, err := tx.Query(sqlSelectDocuments)
+	if err != nil {
+		return nil, failure.Wrap(failure.Storage, fmt.Errorf("retrieving documents from database: %w", err))
+	}
+	defer func() { _ = rows.Close() }()
+
+	var documents []storedDocument
+	for rows.Next() {
+		var d storedDocument
+		err = rows.Scan(&d.id, &d.name)
+		if err != nil {
+			return nil, failure.Wrap(failure.Storage, fmt.Errorf("retrieving documents from database: %w", err))
+		}
+		documents = append(documents, d)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, failure.Wrap(failure.Storage, fmt.Errorf("retrieving documents from database: %w", err))
+	}
+	return documents, nil
+}
+
+// Print a warning on stderr for each input file that isn't stored in the database, since the input files are only inserted when the database is created
+func warnUnstored(dbFile string, inputFiles []string, result *report.Result) {
+	for _, inputFile := range inputFiles {
+		stored := slices.ContainsFunc(result.Documents, func(d report.Document) bool {
+			return filepath.Clean(d.Input) == filepath.Clean(inputFile)
+		})
+		if !stored {
+			failure.Warn(os.Stderr, "persistent_tables", fmt.Sprintf("%s isn't stored in %s, which already exists, so it isn't counted", inputFile, dbFile), fmt.Sprintf("run 'persistent_tables add %s %s' to add it", inputFile, dbFile))
+		}
+	}
+}
+
+// Declare the SQL statements the style runs on the plan p. The tables are only created and filled when the database file doesn't exist yet, otherwise only the queries that read it run
+func describe(p *explain.Plan, cfg *cli.Config) {
+	exists, _ := fileExists(cfg.DatabaseFile)
+	if !exists {
+		p.Stage("create tables", sqlCreateDocuments, sqlCreateWords, sqlCreateStopWords, sqlCreateCasing)
+		p.Stage("insert stop words", sqlInsertStopWord+", for each stop word")
+		p.Stage("insert document", sqlInsertDocument+", for each input file", sqlSelectDocumentId)
+		p.Stage("insert words", sqlSelectStopWords, sqlSelectLastWordId, sqlInsertWord+", for each word that isn't a stop word")
+		p.Stage("delete evicted words", sqlCreateKeptWords, sqlInsertKeptWord+", for each word kept", sqlDeleteEvictedWords, sqlDropKeptWords,
+			sqlDeleteStoredWord+", "+sqlDeleteStoredCasing+" and "+sqlAddEvictedTokens+", for each word evicted from a document inserted before")
+		p.Stage("update token counts", sqlUpdateTokenCounts)
+		p.Stage("insert casing", sqlInsertCasing+", for each word stored")
+	}
+	p.Stage("read documents", sqlSelectDocuments)
+	p.Stage("choose documents", sqlCreateChosen, sqlInsertChosen+", for each document read")
+	p.Stage("count words", sqlSelectWordFreqs)
+	p.Stage("sum token counts", sqlSelectDocumentColumns, sqlSelectTokenCounts)
+	if cfg.Output.NeedsCasing() {
+		p.Stage("read casing", sqlCreateCasing, sqlSelectUncased, sqlSelectCasing)
+	}
+	if exists {
+		p.Note("%s already exists, so the input files aren't inserted and only the queries run, 'persistent_tables add' adds them", cfg.DatabaseFile)
+	} else {
+		p.Note("%s doesn't exist, so it's created with the stop words and the input files, every input file is inserted in a transaction of its own, and the words are only evicted when the limits are full", cfg.DatabaseFile)
+	}
+}
+
+// Create the tables of a new database and insert the stop words and the words of every input file into them within the limits l, reporting the rows inserted to p and adding the rows committed to the plan, and return the number of documents stored.
+// The tables and the stop words are inserted in a transaction, and every document in a transaction of its own, so when ctx is canceled only the document being inserted is rolled back, and the database keeps the documents stored before it
+func createDatabase(ctx context.Context, db *sql.DB, stopWordsFile string, inputFiles []string, filter *tokens.Filter, l *limits.Limits, p *progress.Progress, plan *explain.Plan) (int, error) {
+	var stopWords int
+	err := inTransaction(ctx, db, func(tx *sql.Tx) error {
+		err := createTables(tx)
+		if err != nil {
+			return err
+		}
+		stopWords, err = insertStopWords(tx, stopWordsFile, filter)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	plan.Add("create tables", "table", 4)
+	plan.Add("insert stop words", "row", int64(stopWords))
+
+	var docIds []int
+	for i, inputFile := range inputFiles {
+		var inserted insertedRows
+		err = inTransaction(ctx, db, func(tx *sql.Tx) error {
+			inserted, err = insertData(ctx, tx, i, inputFile, docIds, filter, l, p)
+			return err
+		})
+		if err != nil {
+			return i, err
+		}
+		docIds = append(docIds, inserted.docId)
+		plan.Add("insert document", "row", 1)
+		plan.Add("insert words", "row", int64(inserted.words))
+		plan.Add("delete evicted words", "row", inserted.evicted+inserted.evictedBefore)
+		plan.Add("update token counts", "row", 1)
+		plan.Add("insert casing", "row", int64(inserted.casing))
+	}
+	return len(inputFiles), nil
+}
+
+// Run f in a transaction, which is committed if f succeeds and rolled back otherwise.
+// If ctx is canceled, the transaction is rolled back and the error of ctx is returned instead of the error it caused f or the commit to fail with
+func inTransaction(ctx context.Context, db *sql.DB, f func(tx *sql.Tx) error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return failure.Wrap(failure.Storage, err)
+	}
+
+	err = f(tx)
+	if err == nil {
+		err = tx.Commit()
+		if err == nil {
+			return nil
+		}
+		err = failure.Wrap(failure.Storage, err)
+	} else {
+		_ = tx.Rollback()
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// Check if a file exists
+func fileExists(path string) (bool, error) {
+	info, err := os.Stat(path)
+	if err == nil {
+		return !info.IsDir(), nil
+	}
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+// Create the required tables in the database
+func createTables(tx *sql.Tx) error {
+	_, err := tx.Exec(sqlCreateDocuments)
+	if err != nil {
+		return failure.Wrap(failure.Storage, fmt.Errorf("creating documents table: %w", err))
+	}
+	_, err = tx.Exec(sqlCreateWords)
+	if err != nil {
+		return failure.Wrap(failure.Storage, fmt.Errorf("creating words table: %w", err))
+	}
+	_, err = tx.Exec(sqlCreateStopWords)
+	if err != nil {
+		return failure.Wrap(failure.Storage, fmt.Errorf("creating stop words table: %w", err))
+	}
+	_, err = tx.Exec(sqlCreateCasing)
+	if err != nil {
+		return failure.Wrap(failure.Storage, fmt.Errorf("creating casing table: %w", err))
+	}
+	return nil
+}
+
+// Read the whole file at the given path, the bytes read count towards the limits l, which are nil for files that aren't input files
+func readFile(path string, l *limits.Limits) (data []byte, err error) {
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	defer func(file *os.File) {
+		closeErr := file.Close()
+		if err == nil {
+			err = closeErr
+		}
+	}(file)
+
+	return l.ReadAll(path, file)
+}
+
+// Insert the words from the stop words file into the stop_words table, and return the number of rows inserted
+func insertStopWords(tx *sql.Tx, stopWordsFile string, filter *tokens.Filter) (int, error) {
+	bytes, err := readFile(stopWordsFile, nil)
+	if err != nil {
+		return 0, failure.Wrap(failure.StopWords, err)
+	}
+
+	stopWords := strings.Fields(filter.Normalize(bytes))
+	for _, word := range stopWords {
+		_, err = tx.Exec(sqlInsertStopWord, word)
+		if err != nil {
+			return 0, failure.Wrap(failure.Storage, fmt.Errorf("inserting stop words into database: %w", err))
+		}
+	}
+	return len(stopWords), nil
+}
+
+// Number of words inserted between checks of the context of the run
+const checkInterval = 1024
+
+// insertedRows holds the id of a document, the number of rows of the words table inserted for it, the number of them deleted when words were evicted,
+// the number of rows of the documents inserted before it that were deleted when their words were evicted, and the number of rows of the casing table inserted for it
+type insertedRows struct {
+	docId         int
+	words         int
+	evicted       int64
+	evictedBefore int64
+	casing        int
+}
+
+// Insert the words from the input file that pass the token filter into the words table, along with a new entry in the documents table referring to the input file itself, and return the number of rows inserted.
+// The insertion stops with the error of ctx if it is canceled. The limits l apply to the words of the documents inserted by the run together, whose ids are in stored, so the words they evict are deleted from these documents too.
+// The rows inserted are reported to p as the progress of the input file at index doc
+func insertData(ctx context.Context, tx *sql.Tx, doc int, inputFile string, stored []int, filter *tokens.Filter, l *limits.Limits, p *progress.Progress) (insertedRows, error) {
+	bytes, err := readFile(inputFile, l)
+	if err != nil {
+		return insertedRows{}, failure.Wrap(failure.Input, err)
+	}
+
+	// The casing is scanned before the input is normalized, and stored for the words of the document, so the words can be ranked with their casing from the database alone
+	casingStats := casing.Scan(bytes, filter.IsTokenChar)
+	filteredInput := filter.Normalize(bytes)
+	words := strings.Fields(filteredInput)
+
+	_, err = tx.Exec(sqlInsertDocument, inputFile)
+	if err != nil {
+		return insertedRows{}, failure.Wrap(failure.Storage, fmt.Errorf("inserting new document into database: %w", err))
+	}
+	var docId int
+	err = tx.QueryRow(sqlSelectDocumentId, inputFile).Scan(&docId)
+	if err != nil {
+		return insertedRows{}, failure.Wrap(failure.Storage, fmt.Errorf("getting new document id: %w", err))
+	}
+
+	var stopWords []string
+	rows, err := tx.Query(sqlSelectStopWords)
+	if err != nil {
+		return insertedRows{}, failure.Wrap(failure.Storage, fmt.Errorf("retrieving stop words from database: %w", err))
+	}
+
+	for rows.Next() {
+		var word string
+		err = rows.Scan(&word)
+		if err != nil {
+			_ = rows.Close()
+			return insertedRows{}, failure.Wrap(failure.Storage, fmt.Errorf("retrieving stop words from database: %w", err))
+		}
+		stopWords = append(stopWords, word)
+	}
+	if err = rows.Err(); err != nil {
+		return insertedRows{}, failure.Wrap(failure.Storage, fmt.Errorf("retrieving stop words from database: %w", err))
+	}
+
+	// The words table of a new database is empty, so the last id is 0 instead of NULL
+	var wordId int
+	err = tx.QueryRow(sqlSelectLastWordId).Scan(&wordId)
+	if err != nil {
+		return insertedRows{}, failure.Wrap(failure.Storage, fmt.Errorf("getting last word id: %w", err))
+	}
+	wordId++
+	firstWordId := wordId
+	kept := 0
+	// The frequencies of the words stored for the document, the words the limits evict are removed from it, and only the casing of the words left in it is stored.
+	// starts holds the id of the first row of each of these words, rows of evicted words are deleted once the document is inserted, and a word that comes back after it was evicted starts over from a new row
+	counts := make(map[string]int)
+	starts := make(map[string]int)
+	evicted := false
+	var evictedBefore int64
+	for i, word := range words {
+		if i%checkInterval == 0 {
+			if i > 0 {
+				p.AddTokens(checkInterval)
+			}
+			p.Checkpoint(doc, "inserting rows", int64(i), int64(len(words)))
+			p.SetUniqueWords(len(counts))
+			if ctx.Err() != nil {
+				return insertedRows{}, ctx.Err()
+			}
+		}
+		if !filter.Keep(word) {
+			continue
+		}
+		kept++
+		if slices.Contains(stopWords, word) {
+			continue
+		}
+		evictedWords, err := l.Admit(inputFile, word, 1)
+		if err != nil {
+			return insertedRows{}, err
+		}
+		for _, w := range evictedWords {
+			if _, ok := counts[w]; ok {
+				delete(counts, w)
+				delete(starts, w)
+				evicted = true
+			}
+			deleted, err := deleteStoredWord(tx, stored, w)
+			if err != nil {
+				return insertedRows{}, err
+			}
+			evictedBefore += deleted
+		}
+		if _, ok := counts[word]; !ok {
+			starts[word] = wordId
+		}
+		counts[word]++
+
+		_, err = tx.Exec(sqlInsertWord, wordId, docId, word)
+		if err != nil {
+			return insertedRows{}, failure.Wrap(failure.Storage, fmt.Errorf("inserting data: %w", err))
+		}
+		wordId++
+	}
+
+	inserted := insertedRows{docId: docId, words: wordId - firstWordId, evictedBefore: evictedBefore}
+	if evicted {
+		inserted.evicted, err = deleteEvictedWords(tx, docId, starts)
+		if err != nil {
+			return insertedRows{}, err
+		}
+	}
+
+	// Store the number of tokens in the document, how many of them passed the token filter, and how many of those were deleted when their words were evicted
+	_, err = tx.Exec(sqlUpdateTokenCounts, len(words), kept, inserted.evicted, docId)
+	if err != nil {
+		return insertedRows{}, failure.Wrap(failure.Storage, fmt.Errorf("updating document token counts: %w", err))
+	}
+
+	// Only the casing of the words still stored is inserted, the words that were stop words, rejected by the filter or evicted have none
+	for word := range counts {
+		s, ok := casingStats[word]
+		if !ok {
+			continue
+		}
+		_, err = tx.Exec(sqlInsertCasing, docId, word, s.Lower, s.Title, s.Upper, s.Mixed, s.MidSentence, s.CapitalizedMidSentence)
+		if err != nil {
+			return insertedRows{}, failure.Wrap(failure.Storage, fmt.Errorf("inserting casing: %w", err))
+		}
+		inserted.casing++
+	}
+	return inserted, nil
+}
+
+// Delete the rows of the document's words that were evicted, and keep the rows of the words in starts from the id of their first row on.
+// The kept words go into a temporary table, so a single statement deletes the other rows instead of a statement for every eviction. The number of rows deleted is returned
+func deleteEvictedWords(tx *sql.Tx, docId int, starts map[string]int) (int64, error) {
+	_, err := tx.Exec(sqlCreateKeptWords)
+	if err != nil {
+		return 0, failure.Wrap(failure.Storage, fmt.Errorf("evicting words: %w", err))
+	}
+	for word, start := range starts {
+		_, err = tx.Exec(sqlInsertKeptWord, word, start)
+		if err != nil {
+			return 0, failure.Wrap(failure.Storage, fmt.Errorf("evicting words: %w", err))
+		}
+	}
+	result, err := tx.Exec(sqlDeleteEvictedWords, docId)
+	var deleted int64
+	if err == nil {
+		deleted, err = result.RowsAffected()
+	}
+	if err == nil {
+		_, err = tx.Exec(sqlDropKeptWords)
+	}
+	if err != nil {
+		return 0, failure.Wrap(failure.Storage, fmt.Errorf("evicting words: %w", err))
+	}
+	return deleted, nil
+}
+
+// Delete the rows of word from the documents with the given ids, with its casing, and add them to the evicted tokens of these documents, then return the number of rows deleted
+func deleteStoredWord(tx *sql.Tx, docIds []int, word string) (int64, error) {
+	var deleted int64
+	for _, docId := range docIds {
+		result, err := tx.Exec(sqlDeleteStoredWord, docId, word)
+		var n int64
+		if err == nil {
+			n, err = result.RowsAffected()
+		}
+		if err == nil && n > 0 {
+			_, err = tx.Exec(sqlDeleteStoredCasing, docId, word)
+			if err == nil {
+				_, err = tx.Exec(sqlAddEvictedTokens, n, docId)
+			}
+		}
+		if err != nil {
+			return 0, failure.Wrap(failure.Storage, fmt.Errorf("evicting words: %w", err))
+		}
+		deleted += n
+	}
+	return deleted, nil
+}
@@ -0,0 +1,288 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/cli"
+	"github.com/R0Xps/exercises-in-style-go/internal/explain"
+	"github.com/R0Xps/exercises-in-style-go/internal/failure"
+	"github.com/R0Xps/exercises-in-style-go/internal/limits"
+	"github.com/R0Xps/exercises-in-style-go/internal/progress"
+	"github.com/R0Xps/exercises-in-style-go/internal/report"
+	"github.com/R0Xps/exercises-in-style-go/internal/tokens"
+)
+
+// Style is the pipeline style, which is run by its own binary and by eis
+var Style = cli.Style{Name: "pipeline", Run: run, Explain: describe}
+
+func run(ctx context.Context, cfg *cli.Config) error {
+	// Call functions in order. Each function is explained below, reading a file is the only step that can fail, so the pipeline stops at the first file that can't be read
+	stopWords, err := readStopWords(cfg.Filter)(cfg.StopWordsFile)
+	if err != nil {
+		return failure.Wrap(failure.StopWords, err)
+	}
+	cfg.Explain.Add("reading stop words", "word", int64(len(stopWords)))
+	documents, err := countDocuments(ctx, cfg, stopWords)(cfg.InputFiles)
+	if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+		return report.Interrupted(cfg.Stdout, &report.Result{Style: "pipeline", Documents: documents}, cfg.Output, err)
+	}
+	if err != nil {
+		return failure.Wrap(failure.Input, err)
+	}
+	for _, document := range documents {
+		measure(cfg.Explain, "converting", document)
+	}
+	return printTop25(cfg.Stdout, cfg.Output)(documents)
+}
+
+// Return a function that runs the term frequency functions in order on each of the given input files, and returns a report document for each of them.
+// Every document goes through the whole pipeline at once, so if ctx is canceled the function stops before the next document or while counting the words of one, and returns the documents counted until then along with the error of ctx.
+// The stages report to the progress and the plan of cfg, which are given to each of them along with the index of the document, so they don't share anything but their parameters
+func countDocuments(ctx context.Context, cfg *cli.Config, stopWords []string) func([]string) ([]report.Document, error) {
+	return func(inputPaths []string) ([]report.Document, error) {
+		p, plan := cfg.Progress, cfg.Explain
+		documents := make([]report.Document, 0, len(inputPaths))
+		for i, inputPath := range inputPaths {
+			if ctx.Err() != nil {
+				return documents, ctx.Err()
+			}
+			inputBytes, err := failingStage(p, plan, i, "reading", readInputFile(cfg.Limits))(inputPath)
+			if err != nil {
+				return nil, err
+			}
+			c, err := failingStage(p, plan, i, "counting", frequencies(ctx, cfg.Limits, p, i, inputPath))(stage(p, plan, i, "removing stop words", removeStopWords(stopWords))(stage(p, plan, i, "filtering", filterTokens(cfg.Filter))(stage(p, plan, i, "splitting", split)(stage(p, plan, i, "normalizing", filterAndNormalize(cfg.Filter))(recordCasing(cfg.Output)(inputBytes))))))
+			if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+				// The words counted before ctx was canceled are kept as a partial document
+				return append(report.WithoutWords(documents, c.evicted), toDocument(inputPath)(sort(c.freq))), err
+			}
+			if err != nil {
+				return nil, err
+			}
+			// The limits apply to the words of all documents together, so the words evicted while counting this one are removed from the documents before it
+			documents = append(report.WithoutWords(documents, c.evicted), toDocument(inputPath)(stage(p, plan, i, "sorting", sort)(c.freq)))
+		}
+		return documents, nil
+	}
+}
+
+// The stages every document goes through, in order
+var stages = []string{"reading", "normalizing", "splitting", "filtering", "removing stop words", "counting", "sorting"}
+
+// Return a function that reports to the progress p that the document at index doc reaches the stage with the given name, then calls f and adds the size of what it returns to the stage of the plan
+func stage[T, U any](p *progress.Progress, plan *explain.Plan, doc int, name string, f func(T) U) func(T) U {
+	return func(x T) U {
+		p.Checkpoint(doc, name, int64(slices.Index(stages, name)), int64(len(stages)))
+		y := f(x)
+		measure(plan, name, y)
+		return y
+	}
+}
+
+// Return a function like the one returned by stage for a function f that can fail, the size of what f returns is only added to the plan if it succeeds
+func failingStage[T, U any](p *progress.Progress, plan *explain.Plan, doc int, name string, f func(T) (U, error)) func(T) (U, error) {
+	return func(x T) (U, error) {
+		p.Checkpoint(doc, name, int64(slices.Index(stages, name)), int64(len(stages)))
+		y, err := f(x)
+		if err == nil {
+			measure(plan, name, y)
+		}
+		return y, err
+	}
+}
+
+// The functions the stages of the plan call, with their types, by the name of the stage
+var functions = map[string]string{
+	"reading stop words":  "readStopWords(filter) func(string) ([]string, error)",
+	"reading":             "readInputFile(limits) func(string) ([]byte, error)",
+	"normalizing":         "filterAndNormalize(filter) func([]byte) string",
+	"splitting":           "split func(string) []string",
+	"filtering":           "filterTokens(filter) func([]string) []string",
+	"removing stop words": "removeStopWords(stopWords) func([]string) []string",
+	"counting":            "frequencies(ctx, limits, progress, doc, path) func([]string) (counted, error)",
+	"sorting":             "sort func(map[string]int) []wordFreqEntry",
+	"converting":          "toDocument(inputPath) func([]wordFreqEntry) report.Document",
+	"printing":            "printTop25(w, output) func([]report.Document) error",
+}
+
+// Declare the stages of the pipeline on the plan p: reading the stop words, the stages every document goes through, then converting and printing the documents
+func describe(p *explain.Plan, _ *cli.Config) {
+	for _, name := range slices.Concat([]string{"reading stop words"}, stages, []string{"converting", "printing"}) {
+		p.Stage(name, functions[name])
+	}
+	p.Note("Every document goes through the composition toDocument(sort(frequencies(removeStopWords(filterTokens(split(filterAndNormalize(recordCasing(readInputFile(path))))))))), and the documents are printed together. The words the limits evict while counting a document are removed from the documents before it")
+}
+
+// Add the size of the value returned by the stage with the given name to the plan
+func measure(plan *explain.Plan, name string, value any) {
+	switch v := value.(type) {
+	case []byte:
+		plan.Add(name, "byte", int64(len(v)))
+	case string:
+		plan.Add(name, "byte", int64(len(v)))
+	case []string:
+		plan.Add(name, "word", int64(len(v)))
+	case map[string]int:
+		plan.Add(name, "distinct word", int64(len(v)))
+	case counted:
+		plan.Add(name, "distinct word", int64(len(v.freq)))
+	case []wordFreqEntry:
+		plan.Add(name, "entry", int64(len(v)))
+	case report.Document:
+		plan.Add(name, "document", 1)
+	}
+}
+
+// Return a function that reads the file from the given path and returns its contents as a slice of bytes, the bytes read count towards the limits l, which are nil for files that aren't input files
+func readInputFile(l *limits.Limits) func(string) ([]byte, error) {
+	return func(filePath string) (fileBytes []byte, err error) {
+		file, err := os.Open(filepath.Clean(filePath))
+		if err != nil {
+			return nil, err
+		}
+		defer func(file *os.File) {
+			closeErr := file.Close()
+			if err == nil {
+				err = closeErr
+			}
+		}(file)
+
+		return l.ReadAll(filePath, file)
+	}
+}
+
+// Return a function that reads the stop words file from the given path, and returns the words in it split the same way as the input
+func readStopWords(filter *tokens.Filter) func(string) ([]string, error) {
+	return func(stopWordsPath string) ([]string, error) {
+		stopWordsBytes, err := readInputFile(nil)(stopWordsPath)
+		if err != nil {
+			return nil, err
+		}
+		return split(filterAndNormalize(filter)(stopWordsBytes)), nil
+	}
+}
+
+// Return a function that records the casing of the given bytes of an input file in the report options output, if the report needs it, and returns them as they are so they can be normalized
+func recordCasing(output *report.Options) func([]byte) []byte {
+	return func(inputBytes []byte) []byte {
+		output.RecordCasing(inputBytes)
+		return inputBytes
+	}
+}
+
+// Return a function that replaces all non-token characters with spaces, and converts all uppercase letters to lowercase, then returns the result as a string
+func filterAndNormalize(filter *tokens.Filter) func([]byte) string {
+	return filter.Normalize
+}
+
+// Split the string around spaces and return a slice of strings containing all the words in the given string
+func split(str string) []string {
+	return strings.Fields(str)
+}
+
+// Return a function that returns a new slice containing only the words kept by the token filter (length, character class and pattern checks)
+func filterTokens(filter *tokens.Filter) func([]string) []string {
+	return filter.Apply
+}
+
+// Here I used currying to convert a function that takes multiple arguments removeStopWords(stopWords []string, allWords []string) []string to a sequence of 2 functions
+func removeStopWords(stopWords []string) func([]string) []string {
+	// Return a new slice of strings containing only words that should be counted (non-stop words)
+	return func(allWords []string) []string {
+		words := make([]string, 0)
+		for _, w := range allWords {
+			if !slices.Contains(stopWords, w) {
+				words = append(words, w)
+			}
+		}
+
+		return words
+	}
+}
+
+// Number of words counted between checks of the context of the run
+const checkInterval = 1024
+
+// counted is what frequencies returns for a document: a map where the words are the keys and the values are their frequencies, and the words the limits evicted from the documents before it
+type counted struct {
+	freq    map[string]int
+	evicted []string
+}
+
+// Return a function that returns the frequencies of the words in the given words slice of the document at index doc, whose input file is at path.
+// The map keeps as many distinct words as the limits l allow for all documents together, or the function returns their error if they fail instead.
+// Counting is the longest stage of the pipeline, so the words counted are reported to p while they are counted, as the part of the stage that is done.
+// If ctx is canceled, the function stops counting and returns the words counted so far along with the error of ctx
+func frequencies(ctx context.Context, l *limits.Limits, p *progress.Progress, doc int, path string) func([]string) (counted, error) {
+	return func(words []string) (counted, error) {
+		c := counted{freq: make(map[string]int)}
+		counting := int64(slices.Index(stages, "counting"))
+		for i, word := range words {
+			if i%checkInterval == 0 {
+				p.Checkpoint(doc, "counting", counting*int64(len(words))+int64(i), int64(len(stages))*int64(len(words)))
+				p.AddTokens(int64(min(checkInterval, len(words)-i)))
+				p.SetUniqueWords(len(c.freq))
+				if ctx.Err() != nil {
+					return c, ctx.Err()
+				}
+			}
+			evicted, err := l.Admit(path, word, 1)
+			if err != nil {
+				return counted{}, err
+			}
+			for _, evictedWord := range evicted {
+				delete(c.freq, evictedWord)
+			}
+			c.evicted = append(c.evicted, evicted...)
+			c.freq[word]++
+		}
+		return c, nil
+	}
+}
+
+// wordFreqEntry struct is used to store a word-frequency pair
+type wordFreqEntry struct {
+	word string
+	freq int
+}
+
+// Return a slice of wordFreqEntry containing all entries from the given map, sorted by frequency in descending order
+func sort(freq map[string]int) []wordFreqEntry {
+	wordFreq := make([]wordFreqEntry, 0, len(freq))
+	for k, v := range freq {
+		wordFreq = append(wordFreq, wordFreqEntry{k, v})
+	}
+
+	slices.SortFunc(wordFreq, func(i, j wordFreqEntry) int {
+		return j.freq - i.freq
+	})
+
+	return wordFreq
+}
+
+// Return a function that converts the given sorted list to a report document for the input file at inputPath
+func toDocument(inputPath string) func([]wordFreqEntry) report.Document {
+	return func(wordFreq []wordFreqEntry) report.Document {
+		entries := make([]report.Entry, len(wordFreq))
+		for i, wf := range wordFreq {
+			entries[i] = report.Entry{Word: wf.word, Freq: wf.freq}
+		}
+		return report.Document{Input: inputPath, Entries: entries}
+	}
+}
+
+// Return a function that prints the first 25 elements (or all elements if there are less than 25) of the combined lists of all documents, after the report stage applies the given options to them, to the writer w
+func printTop25(w io.Writer, output *report.Options) func([]report.Document) error {
+	return func(documents []report.Document) error {
+		result := &report.Result{
+			Style:     "pipeline",
+			Documents: documents,
+		}
+		return report.Print(w, result, output)
+	}
+}
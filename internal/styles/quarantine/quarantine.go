@@ -0,0 +1,332 @@
+package quarantine
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/cli"
+	"github.com/R0Xps/exercises-in-style-go/internal/explain"
+	"github.com/R0Xps/exercises-in-style-go/internal/failure"
+	"github.com/R0Xps/exercises-in-style-go/internal/limits"
+	"github.com/R0Xps/exercises-in-style-go/internal/report"
+	"github.com/R0Xps/exercises-in-style-go/internal/tokens"
+)
+
+// Style is the quarantine style, which is run by its own binary and by eis
+var Style = cli.Style{Name: "quarantine", Run: run, Explain: describe}
+
+// The number of words frequencies counts between two checks of the context
+const cancelCheckInterval = 1024
+
+// chainValue is the value the functions of the chain pass to each other: the parsed command line and the context of the run, which only the IO actions use, and what the functions made of the documents so far.
+// The command line holds the paths of the files, the token filter used to split the files into words and drop unwanted tokens, and the options used by the report stage to print the results
+type chainValue struct {
+	ctx       context.Context
+	cfg       *cli.Config
+	documents any
+}
+
+// Return a copy of c holding the given documents
+func (c chainValue) with(documents any) chainValue {
+	c.documents = documents
+	return c
+}
+
+func run(ctx context.Context, cfg *cli.Config) error {
+	// Create a new quarantine object, bind all functions to it, then execute them in order, starting with the command line and the context of the run
+	val, err := chain().Execute(chainValue{ctx: ctx, cfg: cfg})
+	if ctx.Err() != nil && err == ctx.Err() {
+		return report.Interrupted(cfg.Stdout, partialResult(val), cfg.Output, err)
+	}
+	return err
+}
+
+// Return the result of a canceled run from the value returned by the last function that ran.
+// Every function goes through all the documents at once, so words are only counted once frequencies has run, and the documents are empty if the run was canceled before it
+func partialResult(val chainValue) *report.Result {
+	var wordFreq [][]wordFreqEntry
+	switch v := val.documents.(type) {
+	case []map[string]int:
+		wordFreq = sort(val).(chainValue).documents.([][]wordFreqEntry)
+	case [][]wordFreqEntry:
+		wordFreq = v
+	}
+	return newResult(val.cfg.InputFiles, wordFreq)
+}
+
+// Return a new quarantine object with all the functions of the term frequency task bound to it, in order
+func chain() *Quarantine {
+	return NewQuarantine(getInput).Bind(extractWords).Bind(filterTokens).Bind(removeStopWords).Bind(frequencies).Bind(sort).Bind(top25)
+}
+
+// Declare the functions of the chain on the plan p, in the order they are bound
+func describe(p *explain.Plan, _ *cli.Config) {
+	for i, f := range chain().functions {
+		name := functionName(f)
+		if i == 0 {
+			p.Stage(name, "NewQuarantine("+name+")")
+		} else {
+			p.Stage(name, ".Bind("+name+")")
+		}
+	}
+	p.Note("Every function works on all the documents at once, and the functions that do IO or have other side effects return them as a func() any that Execute runs, so nothing is read or counted before Execute is called")
+}
+
+type Quarantine struct {
+	functions []func(any) any
+}
+
+// Create and return a pointer to a new Quaranitine object with f as the first entry in its functions slice
+func NewQuarantine(f func(any) any) *Quarantine {
+	return &Quarantine{
+		functions: []func(any) any{f},
+	}
+}
+
+// Add another function to q's functions slice
+func (q *Quarantine) Bind(f func(any) any) *Quarantine {
+	q.functions = append(q.functions, f)
+	return q
+}
+
+// Execute the functions in q's functions slice in order, giving val to the first one and the output of each function as input to the next, and return the value returned by the last function that ran.
+// IO functions return errors as their value, in which case the remaining functions are skipped and the error is returned.
+// The remaining functions are also skipped if the context of the run is canceled, which is checked before running each of them.
+// Every function works on all the documents at once, so the function that is running is reported to the progress as the stage of all of them, and the size of the value it returns is added to the plan
+func (q *Quarantine) Execute(val chainValue) (chainValue, error) {
+	guardFunc := func(v any) any {
+		f, ok := v.(func() any)
+		if !ok {
+			return v
+		}
+		return f()
+	}
+	p := val.cfg.Progress
+	plan := val.cfg.Explain
+	for i, f := range q.functions {
+		if val.ctx.Err() != nil {
+			return val, val.ctx.Err()
+		}
+		name := functionName(f)
+		p.Checkpoint(-1, name, int64(i), int64(len(q.functions)))
+		next := f(val)
+		if _, ok := next.(func() any); ok {
+			plan.Stage(name, "returned an IO action, which Execute ran")
+		}
+		next = guardFunc(next)
+		if err, ok := next.(error); ok {
+			return val, err
+		}
+		val = next.(chainValue)
+		measure(plan, name, val.documents)
+	}
+	return val, nil
+}
+
+// Add the size of the value returned by the function with the given name to the plan
+func measure(plan *explain.Plan, name string, val any) {
+	switch v := val.(type) {
+	case []string:
+		plan.Add(name, "path", int64(len(v)))
+	case [][]string:
+		plan.Add(name, "document", int64(len(v)))
+		for _, words := range v {
+			plan.Add(name, "word", int64(len(words)))
+		}
+	case []map[string]int:
+		plan.Add(name, "document", int64(len(v)))
+		for _, wfMap := range v {
+			plan.Add(name, "distinct word", int64(len(wfMap)))
+		}
+	case [][]wordFreqEntry:
+		plan.Add(name, "document", int64(len(v)))
+		for _, wordFreq := range v {
+			plan.Add(name, "entry", int64(len(wordFreq)))
+		}
+	}
+}
+
+// Return the name of the function f without its package, like extractWords
+func functionName(f func(any) any) string {
+	name := runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// Return a function that returns the paths to the input files
+func getInput(v any) any {
+	c := v.(chainValue)
+	return func() any {
+		return c.with(c.cfg.InputFiles)
+	}
+}
+
+// Return a function that returns a slice of strings containing all words from the file at filePath split by the token filter, or the error reading it.
+// The bytes read count towards the limits l, and their casing is recorded in the report options output, which are both nil for files that aren't input files
+func readWords(filePath string, filter *tokens.Filter, l *limits.Limits, output *report.Options) func() any {
+	return func() (words any) {
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		// An error closing the file is returned instead of the words, unless reading it already failed
+		defer func(file *os.File) {
+			closeErr := file.Close()
+			if _, failed := words.(error); closeErr != nil && !failed {
+				words = closeErr
+			}
+		}(file)
+
+		bytes, err := l.ReadAll(filePath, file)
+		if err != nil {
+			return err
+		}
+
+		output.RecordCasing(bytes)
+		return strings.Fields(filter.Normalize(bytes))
+	}
+}
+
+// Return a function that returns a slice of words for each of the files whose paths it is given, or the error reading the first file that can't be read
+func extractWords(v any) any {
+	c := v.(chainValue)
+	return func() any {
+		documents := make([][]string, 0)
+		for _, filePath := range c.documents.([]string) {
+			words := readWords(filePath, c.cfg.Filter, c.cfg.Limits, c.cfg.Output)()
+			if err, ok := words.(error); ok {
+				return failure.Wrap(failure.Input, err)
+			}
+			documents = append(documents, words.([]string))
+		}
+		return c.with(documents)
+	}
+}
+
+// Return a function that returns a slice containing only the words of each document that pass the token filter.
+// The token filter counts the tokens it keeps and rejects for the report, so filtering is an IO action like reading
+func filterTokens(v any) any {
+	c := v.(chainValue)
+	return func() any {
+		filtered := make([][]string, 0)
+		for _, words := range c.documents.([][]string) {
+			filtered = append(filtered, c.cfg.Filter.Apply(words))
+		}
+		return c.with(filtered)
+	}
+}
+
+// Return a function that returns a slice containing all non-stop words from the words slice of each document, or the error reading the stop words
+func removeStopWords(v any) any {
+	c := v.(chainValue)
+	return func() any {
+		words := readWords(c.cfg.StopWordsFile, c.cfg.Filter, nil, nil)()
+		if err, ok := words.(error); ok {
+			return failure.Wrap(failure.StopWords, err)
+		}
+		stopWords := words.([]string)
+		nonStopDocuments := make([][]string, 0)
+		for _, allWords := range c.documents.([][]string) {
+			nonStopWords := make([]string, 0)
+			for _, word := range allWords {
+				if !slices.Contains(stopWords, word) {
+					nonStopWords = append(nonStopWords, word)
+				}
+			}
+			nonStopDocuments = append(nonStopDocuments, nonStopWords)
+		}
+		return c.with(nonStopDocuments)
+	}
+}
+
+// Return a function that returns a map for each document containing all words from its words slice with their frequencies.
+// Counting checks the context of the run, reports the words counted to the progress, and counts them in the limits, so it is an IO action too.
+// The maps keep as many distinct words as the limits allow for all documents together, a word they evict is removed from every map, and the error of the document where there are too many of them is returned instead if the limits fail in that case.
+// If the run is canceled, the counting stops and the maps of the documents counted so far are returned, the last one with the words counted before the cancellation
+func frequencies(v any) any {
+	c := v.(chainValue)
+	return func() any {
+		wfMaps := make([]map[string]int, 0)
+		for i, wordsSlice := range c.documents.([][]string) {
+			wfMap := make(map[string]int)
+			wfMaps = append(wfMaps, wfMap)
+			for j, word := range wordsSlice {
+				if j%cancelCheckInterval == 0 && c.ctx.Err() != nil {
+					c.cfg.Progress.AddTokens(int64(j))
+					return c.with(wfMaps)
+				}
+				evicted, err := c.cfg.Limits.Admit(c.cfg.InputFiles[i], word, 1)
+				if err != nil {
+					return err
+				}
+				for _, evictedWord := range evicted {
+					for _, m := range wfMaps {
+						delete(m, evictedWord)
+					}
+				}
+				wfMap[word]++
+			}
+			c.cfg.Progress.AddTokens(int64(len(wordsSlice)))
+			c.cfg.Progress.SetUniqueWords(len(wfMap))
+		}
+		return c.with(wfMaps)
+	}
+}
+
+// wordFreqEntry struct is used to store a word-frequency pair
+type wordFreqEntry struct {
+	word string
+	freq int
+}
+
+// Return a sorted slice for each document containing all entries from its wf map
+func sort(v any) any {
+	c := v.(chainValue)
+	documents := make([][]wordFreqEntry, 0)
+	for _, wfMap := range c.documents.([]map[string]int) {
+		wordFreq := make([]wordFreqEntry, 0)
+		for k, v := range wfMap {
+			wordFreq = append(wordFreq, wordFreqEntry{k, v})
+		}
+
+		slices.SortFunc(wordFreq, func(i, j wordFreqEntry) int {
+			return j.freq - i.freq
+		})
+		documents = append(documents, wordFreq)
+	}
+
+	return c.with(documents)
+}
+
+// Return a function that prints the first 25 (or less if there are less than 25) elements in the combined wordFreq slices of all documents, after the report stage applies its options to them.
+// The function returns the error printing them, if there is one
+func top25(v any) any {
+	c := v.(chainValue)
+	return func() any {
+		err := report.Print(c.cfg.Stdout, newResult(c.cfg.InputFiles, c.documents.([][]wordFreqEntry)), c.cfg.Output)
+		if err != nil {
+			return err
+		}
+		return c.with(nil)
+	}
+}
+
+// Return the result holding the entries of the document of every input file, the documents without a wordFreq slice (which weren't counted before the run was canceled) have no entries
+func newResult(inputFiles []string, wordFreq [][]wordFreqEntry) *report.Result {
+	result := &report.Result{Style: "quarantine"}
+	for i, inputFile := range inputFiles {
+		var wordFreqSlice []wordFreqEntry
+		if i < len(wordFreq) {
+			wordFreqSlice = wordFreq[i]
+		}
+		entries := make([]report.Entry, len(wordFreqSlice))
+		for j, wf := range wordFreqSlice {
+			entries[j] = report.Entry{Word: wf.word, Freq: wf.freq}
+		}
+		result.Documents = append(result.Documents, report.Document{Input: inputFile, Entries: entries})
+	}
+	return result
+}
@@ -0,0 +1,75 @@
+// Package styles lists every style along with its description, which is taken from the style's README
+package styles
+
+//go:generate go run gen_descriptions.go
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/cli"
+	"github.com/R0Xps/exercises-in-style-go/internal/styles/actors"
+	mapreduce "github.com/R0Xps/exercises-in-style-go/internal/styles/map_reduce"
+	"github.com/R0Xps/exercises-in-style-go/internal/styles/monolithic"
+	persistenttables "github.com/R0Xps/exercises-in-style-go/internal/styles/persistent_tables"
+	"github.com/R0Xps/exercises-in-style-go/internal/styles/pipeline"
+	"github.com/R0Xps/exercises-in-style-go/internal/styles/quarantine"
+	"github.com/R0Xps/exercises-in-style-go/internal/styles/things"
+)
+
+// All holds every style, sorted by name
+var All = []cli.Style{
+	actors.Style,
+	mapreduce.Style,
+	monolithic.Style,
+	persistenttables.Style,
+	pipeline.Style,
+	quarantine.Style,
+	things.Style,
+}
+
+// Description is the title of a style and the constraints it follows
+type Description struct {
+	Title       string
+	Constraints []string
+}
+
+// Return the style with the given name, and whether it exists
+func Get(name string) (cli.Style, bool) {
+	for _, s := range All {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return cli.Style{}, false
+}
+
+// Parse the README of a style, and return its title and the items of its "Style Constraints" list.
+// Items that are wrapped over several lines, and the items of nested lists, are joined into a single line
+func ParseDescription(readme []byte) Description {
+	var d Description
+	inConstraints := false
+	scanner := bufio.NewScanner(bytes.NewReader(readme))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case d.Title == "":
+			d.Title = trimmed
+		case trimmed == "Style Constraints:":
+			inConstraints = true
+		case !inConstraints || trimmed == "" || strings.Trim(trimmed, "=") == "":
+			continue
+		case strings.HasPrefix(line, "- "):
+			d.Constraints = append(d.Constraints, strings.TrimPrefix(line, "- "))
+		case strings.HasPrefix(line, " ") && len(d.Constraints) > 0:
+			last := len(d.Constraints) - 1
+			d.Constraints[last] += " " + strings.TrimPrefix(trimmed, "- ")
+		default:
+			// The constraints list ends at the first line that isn't part of it
+			inConstraints = false
+		}
+	}
+	return d
+}
@@ -0,0 +1,43 @@
+package styles
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDescriptionsUpToDate(t *testing.T) {
+	for _, s := range All {
+		readme, err := os.ReadFile(filepath.Join("..", "..", "cmd", s.Name, "README.md"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := ParseDescription(readme); !reflect.DeepEqual(Descriptions[s.Name], want) {
+			t.Errorf("the description of %s is out of date, run go generate ./internal/styles", s.Name)
+		}
+	}
+}
+
+func TestEveryStyleIsListed(t *testing.T) {
+	items, err := os.ReadDir(filepath.Join("..", "..", "cmd"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range items {
+		if !item.IsDir() || item.Name() == "eis" {
+			continue
+		}
+		if _, ok := Get(item.Name()); !ok {
+			t.Errorf("cmd/%s isn't in the styles list", item.Name())
+		}
+	}
+}
+
+func TestParseDescription(t *testing.T) {
+	readme := []byte("Title\n=====\n\nStyle Constraints:\n\n- One.\n- Two that is\n  wrapped:\n  - nested.\n\nBrief explanation:\n\n- Not a constraint.\n")
+	want := Description{Title: "Title", Constraints: []string{"One.", "Two that is wrapped: nested."}}
+	if got := ParseDescription(readme); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+}
@@ -0,0 +1,62 @@
+package things
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/failure"
+	"github.com/R0Xps/exercises-in-style-go/internal/limits"
+	"github.com/R0Xps/exercises-in-style-go/internal/report"
+	"github.com/R0Xps/exercises-in-style-go/internal/tokens"
+)
+
+// DataStorageManager stores the text of the input file as it was read and its words, and can return a slice of all of the words on demand
+type DataStorageManager struct {
+	path  string
+	text  []byte
+	words []string
+}
+
+// Create and return a pointer to a new DataStorageManager object with its words being the words of the normalized contents of the file at inputFilePath that are kept by the token filter, or the error reading the file.
+// The bytes read count towards the size limit of the input files in l, and their casing is recorded in the report options output before they are normalized.
+// The words are filtered once here, so the tokens the filter kept and rejected are only counted once however many times the words are counted
+func NewDataStorageManager(inputFilePath string, filter *tokens.Filter, l *limits.Limits, output *report.Options) (*DataStorageManager, error) {
+	file, err := os.Open(filepath.Clean(inputFilePath))
+	if err != nil {
+		return nil, failure.Wrap(failure.Input, err)
+	}
+
+	rawData, err := l.ReadAll(inputFilePath, file)
+	closeErr := file.Close()
+	if err != nil {
+		return nil, failure.Wrap(failure.Input, err)
+	}
+	if closeErr != nil {
+		return nil, failure.Wrap(failure.Input, closeErr)
+	}
+
+	output.RecordCasing(rawData)
+	words := filter.Apply(strings.Fields(filter.Normalize(rawData)))
+
+	return &DataStorageManager{
+		path:  inputFilePath,
+		text:  rawData,
+		words: words,
+	}, nil
+}
+
+// Return a slice containing the words stored in the DataStorageManager object, the slice is shared by every call so it must not be changed
+func (dsm *DataStorageManager) Words() []string {
+	return dsm.words
+}
+
+// Return the text of the input file as it was read, before it was normalized, the slice is shared by every call so it must not be changed
+func (dsm *DataStorageManager) Text() []byte {
+	return dsm.text
+}
+
+// Return the path of the file the DataStorageManager object was created from
+func (dsm *DataStorageManager) Path() string {
+	return dsm.path
+}
@@ -0,0 +1,74 @@
+package things
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/failure"
+	"github.com/R0Xps/exercises-in-style-go/internal/tokens"
+)
+
+// StopWordsManager handles everything to do with stop words
+type StopWordsManager struct {
+	stopWords []string
+}
+
+// Create and return a pointer to a new StopWordsManager with its stopWords field initialized to the words in the file at stopWordsFilePath (split using the given token filter), or the error reading the file
+func NewStopWordsManager(stopWordsFilePath string, filter *tokens.Filter) (*StopWordsManager, error) {
+	file, err := os.Open(filepath.Clean(stopWordsFilePath))
+	if err != nil {
+		return nil, failure.Wrap(failure.StopWords, err)
+	}
+
+	data, err := io.ReadAll(file)
+	closeErr := file.Close()
+	if err != nil {
+		return nil, failure.Wrap(failure.StopWords, err)
+	}
+	if closeErr != nil {
+		return nil, failure.Wrap(failure.StopWords, closeErr)
+	}
+
+	stopWordsStr := filter.Normalize(data)
+
+	return &StopWordsManager{
+		stopWords: strings.Fields(stopWordsStr),
+	}, nil
+}
+
+// Check if the given word is in the stopWords slice
+func (swm *StopWordsManager) IsStopWord(word string) bool {
+	return slices.Contains(swm.stopWords, word)
+}
+
+// Add the given words to the stopWords slice, words that already are stop words are only kept once
+func (swm *StopWordsManager) Add(words ...string) {
+	for _, word := range words {
+		if !swm.IsStopWord(word) {
+			swm.stopWords = append(swm.stopWords, word)
+		}
+	}
+}
+
+// Remove the given words from the stopWords slice
+func (swm *StopWordsManager) Remove(words ...string) {
+	swm.stopWords = slices.DeleteFunc(swm.stopWords, func(stopWord string) bool {
+		return slices.Contains(words, stopWord)
+	})
+}
+
+// Replace the stopWords slice with the given words, words given several times are only kept once
+func (swm *StopWordsManager) Set(words []string) {
+	swm.stopWords = nil
+	swm.Add(words...)
+}
+
+// Return a sorted copy of the stopWords slice
+func (swm *StopWordsManager) Words() []string {
+	words := slices.Clone(swm.stopWords)
+	slices.Sort(words)
+	return words
+}
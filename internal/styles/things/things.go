@@ -0,0 +1,19 @@
+package things
+
+import (
+	"context"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/cli"
+)
+
+// Style is the things style, which is run by its own binary and by eis
+var Style = cli.Style{Name: "things", Run: run}
+
+func run(ctx context.Context, cfg *cli.Config) error {
+	// Initialize an instance of WordFrequencyController with the arguments passed to the program
+	wfc, err := NewWordFrequencyController(cfg.StopWordsFile, cfg.InputFiles, cfg.Filter, cfg.Limits, cfg.Output, cfg.Stdout)
+	if err != nil {
+		return err
+	}
+	return wfc.Run(ctx, cfg.Progress)
+}
@@ -0,0 +1,146 @@
+package things
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/limits"
+	"github.com/R0Xps/exercises-in-style-go/internal/progress"
+	"github.com/R0Xps/exercises-in-style-go/internal/report"
+	"github.com/R0Xps/exercises-in-style-go/internal/tokens"
+)
+
+// WordFrequencyController holds objects of DataStorageManager, StopWordsManager, and WordFrequencyManager, and uses them together to complete the term frequency task and print its output.
+// There is a DataStorageManager for each input file, and every count has a WordFrequencyManager for each of them, so the words of each file are counted separately
+type WordFrequencyController struct {
+	dataStorageManagers []*DataStorageManager
+	stopWordsManager    *StopWordsManager
+	limits              *limits.Limits
+	output              *report.Options
+	stdout              io.Writer
+}
+
+// Create and return a pointer to a new WordFrequencyController object, with objects of DataStorageManager, StopWordsManager, WordFrequencyManager all initialized with the appropriate values and limits, and the options used to print its output to stdout.
+// The error reading the first file that can't be read is returned instead
+func NewWordFrequencyController(stopWordsFilePath string, inputFilePaths []string, filter *tokens.Filter, l *limits.Limits, output *report.Options, stdout io.Writer) (*WordFrequencyController, error) {
+	stopWordsManager, err := NewStopWordsManager(stopWordsFilePath, filter)
+	if err != nil {
+		return nil, err
+	}
+	wfc := &WordFrequencyController{
+		stopWordsManager: stopWordsManager,
+		limits:           l,
+		output:           output,
+		stdout:           stdout,
+	}
+	for _, inputFilePath := range inputFilePaths {
+		dataStorageManager, err := NewDataStorageManager(inputFilePath, filter, l, output)
+		if err != nil {
+			return nil, err
+		}
+		wfc.dataStorageManagers = append(wfc.dataStorageManagers, dataStorageManager)
+	}
+	return wfc, nil
+}
+
+// Number of words the controller counts between checks of its context
+const checkInterval = 1024
+
+// Run the controller and use the 3 separate objects together to get the desired output and print it, and return the error printing it or the error of the limits if there is one.
+// If ctx is canceled, the controller stops counting and returns the error of ctx, after printing the words counted so far if the output options ask for it.
+// The words counted in each document are reported to p as they are counted
+func (wfc *WordFrequencyController) Run(ctx context.Context, p *progress.Progress) error {
+	result, err := wfc.Count(ctx, p)
+	if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+		return report.Interrupted(wfc.stdout, result, wfc.output, ctx.Err())
+	}
+	if err != nil {
+		return err
+	}
+
+	// Print the first 25 elements (or all elements if there are less than 25) of the combined wordFreq slices
+	return report.Print(wfc.stdout, result, wfc.output)
+}
+
+// Count the words of every input file that aren't stop words with a new WordFrequencyManager for each of them, and return them as the documents of a result, or the error of the limits if there is one.
+// The words are kept by the DataStorageManager objects, so they can be counted again after the stop words change without reading the files again, and the limits start over with every count.
+// The limits apply to all input files together, so the words they evict are removed from every WordFrequencyManager, and the documents are only made once all of them are counted.
+// If ctx is canceled, the controller stops counting and returns the words counted so far along with the error of ctx. The words counted in each document are reported to p as they are counted
+func (wfc *WordFrequencyController) Count(ctx context.Context, p *progress.Progress) (*report.Result, error) {
+	wfc.limits.ResetWords()
+	wordFrequencyManagers := make([]*WordFrequencyManager, 0, len(wfc.dataStorageManagers))
+	for i, dataStorageManager := range wfc.dataStorageManagers {
+		wordFrequencyManager := NewWordFrequencyManager(dataStorageManager.Path(), wfc.limits)
+		wordFrequencyManagers = append(wordFrequencyManagers, wordFrequencyManager)
+
+		words := dataStorageManager.Words()
+		for j, word := range words {
+			if j%checkInterval == 0 {
+				p.Checkpoint(i, "counting", int64(j), int64(len(words)))
+				p.AddTokens(int64(min(checkInterval, len(words)-j)))
+				p.SetUniqueWords(wordFrequencyManager.Len())
+				if ctx.Err() != nil {
+					break
+				}
+			}
+			if !wfc.stopWordsManager.IsStopWord(word) {
+				evicted, err := wordFrequencyManager.Increment(word)
+				if err != nil {
+					return nil, err
+				}
+				for _, m := range wordFrequencyManagers {
+					m.Remove(evicted...)
+				}
+			}
+		}
+
+		if ctx.Err() != nil {
+			return documents(wordFrequencyManagers), ctx.Err()
+		}
+	}
+	return documents(wordFrequencyManagers), nil
+}
+
+// Return a result with the words counted by each of the given WordFrequencyManager objects as its documents
+func documents(wordFrequencyManagers []*WordFrequencyManager) *report.Result {
+	result := &report.Result{Style: "things"}
+	for _, wordFrequencyManager := range wordFrequencyManagers {
+		result.Documents = append(result.Documents, report.Document{Input: wordFrequencyManager.path, Entries: wordFrequencyManager.Entries()})
+	}
+	return result
+}
+
+// Return the text of every input file as it was read, in the order of the input files
+func (wfc *WordFrequencyController) Texts() [][]byte {
+	texts := make([][]byte, 0, len(wfc.dataStorageManagers))
+	for _, dataStorageManager := range wfc.dataStorageManagers {
+		texts = append(texts, dataStorageManager.Text())
+	}
+	return texts
+}
+
+// Return the stop words, sorted
+func (wfc *WordFrequencyController) StopWords() []string {
+	return wfc.stopWordsManager.Words()
+}
+
+// Add the given words to the stop words, they are left out of the next count
+func (wfc *WordFrequencyController) AddStopWords(words ...string) {
+	wfc.stopWordsManager.Add(words...)
+}
+
+// Remove the given words from the stop words, they are counted again by the next count
+func (wfc *WordFrequencyController) RemoveStopWords(words ...string) {
+	wfc.stopWordsManager.Remove(words...)
+}
+
+// Replace the stop words with the given words, they are the only words left out of the next count
+func (wfc *WordFrequencyController) SetStopWords(words ...string) {
+	wfc.stopWordsManager.Set(words)
+}
+
+// Check if the given word is a stop word
+func (wfc *WordFrequencyController) IsStopWord(word string) bool {
+	return wfc.stopWordsManager.IsStopWord(word)
+}
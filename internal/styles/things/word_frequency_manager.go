@@ -0,0 +1,80 @@
+package things
+
+import (
+	"slices"
+
+	"github.com/R0Xps/exercises-in-style-go/internal/limits"
+	"github.com/R0Xps/exercises-in-style-go/internal/report"
+)
+
+// WordFrequencyManager keeps track of the frequency of words, and returns a sorted slice of words and their frequencies on demand
+type WordFrequencyManager struct {
+	freq map[string]int
+	// path is the path of the input file the words come from, and limits bounds how many distinct words are kept from it and the other input files together
+	path   string
+	limits *limits.Limits
+}
+
+// Create and return a pointer to a new WordFrequencyManager object, with an empty frequency map for the words of the file at path that keeps as many distinct words as l allows for all the input files
+func NewWordFrequencyManager(path string, l *limits.Limits) *WordFrequencyManager {
+	freq := make(map[string]int)
+	return &WordFrequencyManager{
+		freq:   freq,
+		path:   path,
+		limits: l,
+	}
+}
+
+// Increment the frequency of the given word, and return the words the limits evicted to make room for it, which the other WordFrequencyManager objects have to remove too.
+// The error of the limits is returned instead if the word is new and there is no room for it
+func (wfm *WordFrequencyManager) Increment(word string) ([]string, error) {
+	evicted, err := wfm.limits.Admit(wfm.path, word, 1)
+	if err != nil {
+		return nil, err
+	}
+	wfm.Remove(evicted...)
+	wfm.freq[word]++
+	return evicted, nil
+}
+
+// Remove the given words from the frequency map, after the limits evicted them
+func (wfm *WordFrequencyManager) Remove(words ...string) {
+	for _, word := range words {
+		delete(wfm.freq, word)
+	}
+}
+
+// Return the number of distinct words counted so far
+func (wfm *WordFrequencyManager) Len() int {
+	return len(wfm.freq)
+}
+
+// wordFreqEntry struct is used to store a word-frequency pair
+type wordFreqEntry struct {
+	word string
+	freq int
+}
+
+// Return a list of words and their frequencies sorted by frequency in descending order
+func (wfm *WordFrequencyManager) Sorted() []wordFreqEntry {
+	wordFreq := make([]wordFreqEntry, 0, len(wfm.freq))
+	for k, v := range wfm.freq {
+		wordFreq = append(wordFreq, wordFreqEntry{word: k, freq: v})
+	}
+
+	slices.SortFunc(wordFreq, func(i, j wordFreqEntry) int {
+		return j.freq - i.freq
+	})
+
+	return wordFreq
+}
+
+// Return the words and their frequencies sorted by frequency in descending order, as the entries of a report document
+func (wfm *WordFrequencyManager) Entries() []report.Entry {
+	wordFreq := wfm.Sorted()
+	entries := make([]report.Entry, len(wordFreq))
+	for i, wf := range wordFreq {
+		entries[i] = report.Entry{Word: wf.word, Freq: wf.freq}
+	}
+	return entries
+}
@@ -0,0 +1,11 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+
+package terminal
+
+import "golang.org/x/sys/unix"
+
+// The requests that get and set the mode of a terminal
+const (
+	getTermios = unix.TIOCGETA
+	setTermios = unix.TIOCSETA
+)
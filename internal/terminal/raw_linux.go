@@ -0,0 +1,9 @@
+package terminal
+
+import "golang.org/x/sys/unix"
+
+// The requests that get and set the mode of a terminal
+const (
+	getTermios = unix.TCGETS
+	setTermios = unix.TCSETS
+)
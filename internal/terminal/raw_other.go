@@ -0,0 +1,18 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package terminal
+
+import (
+	"errors"
+	"os"
+)
+
+// Check if f is a terminal. Terminals that can be put in raw mode are only detected on linux and the BSDs
+func IsTerminal(_ *os.File) bool {
+	return false
+}
+
+// Put the terminal f reads from in raw mode, which is only supported on linux and the BSDs, so it always fails here
+func MakeRaw(_ *os.File) (func() error, error) {
+	return nil, errors.ErrUnsupported
+}
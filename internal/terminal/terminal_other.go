@@ -0,0 +1,24 @@
+//go:build !unix
+
+package terminal
+
+import (
+	"io"
+	"os"
+)
+
+// Return the width in columns of the terminal w writes to, and whether w is a terminal at all. Terminals are only detected on unix systems
+func Width(_ io.Writer) (int, bool) {
+	return 0, false
+}
+
+// Return the width in columns and the height in rows of the terminal w writes to, and whether w is a terminal at all. Terminals are only detected on unix systems
+func Size(_ io.Writer) (int, int, bool) {
+	return 0, 0, false
+}
+
+// Send a signal to c every time the terminal is resized, which is only noticed on unix systems
+func NotifyResize(_ chan<- os.Signal) {}
+
+// Stop sending the resizes of the terminal to c
+func StopResize(_ chan<- os.Signal) {}
@@ -0,0 +1,49 @@
+//go:build unix
+
+// Package terminal finds out about the terminal the program writes to
+package terminal
+
+import (
+	"io"
+	"os"
+	"os/signal"
+
+	"golang.org/x/sys/unix"
+)
+
+// Return the width in columns of the terminal w writes to, and whether w is a terminal at all.
+// Writers that wrap another writer can implement Unwrap() io.Writer, so the terminal of the writer they wrap is found
+func Width(w io.Writer) (int, bool) {
+	width, _, ok := Size(w)
+	return width, ok
+}
+
+// Return the width in columns and the height in rows of the terminal w writes to, and whether w is a terminal at all. Writers that wrap another writer are unwrapped like in Width
+func Size(w io.Writer) (int, int, bool) {
+	for {
+		u, ok := w.(interface{ Unwrap() io.Writer })
+		if !ok {
+			break
+		}
+		w = u.Unwrap()
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return 0, 0, false
+	}
+	size, err := unix.IoctlGetWinsize(int(f.Fd()), unix.TIOCGWINSZ)
+	if err != nil {
+		return 0, 0, false
+	}
+	return int(size.Col), int(size.Row), true
+}
+
+// Send a signal to c every time the terminal is resized, until StopResize is called with c
+func NotifyResize(c chan<- os.Signal) {
+	signal.Notify(c, unix.SIGWINCH)
+}
+
+// Stop sending the resizes of the terminal to c
+func StopResize(c chan<- os.Signal) {
+	signal.Stop(c)
+}
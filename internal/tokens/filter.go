@@ -0,0 +1,119 @@
+// Package tokens contains the token filter stage that every style passes its words through
+package tokens
+
+import (
+	"flag"
+	"fmt"
+	"regexp"
+	"sync/atomic"
+)
+
+// Filter decides which characters make up a token, and which tokens are kept for counting
+type Filter struct {
+	// MinLength is the minimum length of a kept token
+	MinLength int
+	// MaxLength is the maximum length of a kept token, 0 means there is no maximum
+	MaxLength int
+	// Numbers makes digits part of tokens, otherwise they are treated as separators like any other non-letter character
+	Numbers bool
+	// Pattern, when set, has to match an entire token for it to be kept
+	Pattern *regexp.Regexp
+
+	// seen and kept count the tokens passed to Keep, and how many of them were kept. They are atomic since some styles filter tokens concurrently
+	seen atomic.Int64
+	kept atomic.Int64
+}
+
+// Stats holds the number of tokens a filter has seen, and how many of them it kept
+type Stats struct {
+	Tokens int
+	Kept   int
+}
+
+// Create and return a pointer to a new Filter that keeps every token, which matches the original behavior of all styles
+func NewFilter() *Filter {
+	return &Filter{
+		MinLength: 1,
+	}
+}
+
+// Create and return a pointer to a new Filter with the same settings as f, whose counts start from zero
+func (f *Filter) Clone() *Filter {
+	return &Filter{
+		MinLength: f.MinLength,
+		MaxLength: f.MaxLength,
+		Numbers:   f.Numbers,
+		Pattern:   f.Pattern,
+	}
+}
+
+// Register the command-line flags that configure the filter on the given FlagSet
+func (f *Filter) RegisterFlags(fs *flag.FlagSet) {
+	fs.IntVar(&f.MinLength, "min-length", f.MinLength, "ignore tokens shorter than `n` characters")
+	fs.IntVar(&f.MaxLength, "max-length", f.MaxLength, "ignore tokens longer than `n` characters (0 means no limit)")
+	fs.BoolVar(&f.Numbers, "numbers", f.Numbers, "count digits as part of tokens instead of discarding them")
+	fs.Func("token-pattern", "only count tokens entirely matching the `regexp`", f.SetPattern)
+}
+
+// Compile the given expression and use it as the filter's pattern, the expression is anchored so it has to match the whole token
+func (f *Filter) SetPattern(expr string) error {
+	pattern, err := regexp.Compile(`^(?:` + expr + `)$`)
+	if err != nil {
+		return fmt.Errorf("invalid token pattern: %w", err)
+	}
+	f.Pattern = pattern
+	return nil
+}
+
+// Check if the given byte can be part of a token
+func (f *Filter) IsTokenChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || f.Numbers && c >= '0' && c <= '9'
+}
+
+// Replace all non-token characters with spaces, and convert all uppercase letters to lowercase, then return the result as a string
+func (f *Filter) Normalize(data []byte) string {
+	for i, b := range data {
+		if !f.IsTokenChar(b) {
+			b = ' '
+		} else if b >= 'A' && b <= 'Z' {
+			b += 'a' - 'A'
+		}
+		data[i] = b
+	}
+	return string(data)
+}
+
+// Check if the given (normalized) token passes the length and pattern filters
+func (f *Filter) Keep(word string) bool {
+	f.seen.Add(1)
+	if len(word) < f.MinLength {
+		return false
+	}
+	if f.MaxLength > 0 && len(word) > f.MaxLength {
+		return false
+	}
+	if f.Pattern != nil && !f.Pattern.MatchString(word) {
+		return false
+	}
+	f.kept.Add(1)
+	return true
+}
+
+// Return the number of tokens the filter has seen and kept so far
+func (f *Filter) Stats() Stats {
+	return Stats{
+		Tokens: int(f.seen.Load()),
+		Kept:   int(f.kept.Load()),
+	}
+}
+
+// Return a new slice containing only the words that the filter keeps
+func (f *Filter) Apply(words []string) []string {
+	kept := make([]string, 0, len(words))
+	for _, word := range words {
+		if f.Keep(word) {
+			kept = append(kept, word)
+		}
+	}
+	return kept
+}
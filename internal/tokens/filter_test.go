@@ -0,0 +1,46 @@
+package tokens
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	input := "Don't stop: 2 B-52s, x42 and AbC!"
+
+	tests := []struct {
+		name    string
+		setup   func(f *Filter) error
+		want    []string
+		wantErr bool
+	}{
+		{name: "default", setup: func(f *Filter) error { return nil }, want: []string{"don", "t", "stop", "b", "s", "x", "and", "abc"}},
+		{name: "min length", setup: func(f *Filter) error { f.MinLength = 2; return nil }, want: []string{"don", "stop", "and", "abc"}},
+		{name: "max length", setup: func(f *Filter) error { f.MaxLength = 1; return nil }, want: []string{"t", "b", "s", "x"}},
+		{name: "numbers", setup: func(f *Filter) error { f.Numbers = true; return nil }, want: []string{"don", "t", "stop", "2", "b", "52s", "x42", "and", "abc"}},
+		{name: "pattern", setup: func(f *Filter) error { f.Numbers = true; return f.SetPattern(`[0-9]+`) }, want: []string{"2"}},
+		{name: "invalid pattern", setup: func(f *Filter) error { return f.SetPattern(`(`) }, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := NewFilter()
+			err := test.setup(f)
+			if test.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := f.Apply(strings.Fields(f.Normalize([]byte(input))))
+			if !slices.Equal(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
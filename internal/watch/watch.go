@@ -0,0 +1,177 @@
+// Package watch polls files for changes, so a run can be repeated whenever its input changes, and compares the rankings of two runs to show which words moved
+package watch
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Options configure how the files are watched
+type Options struct {
+	// Enabled makes the command run again every time one of its files changes, it is set by --watch
+	Enabled bool
+	// Interval is the time between two checks of the files
+	Interval time.Duration
+}
+
+// Create and return a pointer to new Options that don't watch the files, and check them every second once they do
+func New() *Options {
+	return &Options{Interval: time.Second}
+}
+
+// Register the command-line flags that configure the watch on the given FlagSet
+func (o *Options) RegisterFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&o.Enabled, "watch", o.Enabled, "run again every time the input files or the stop words file change, and show which words moved in the ranking")
+	fs.DurationVar(&o.Interval, "watch-interval", o.Interval, "check the files for changes every `duration`")
+}
+
+// FileState is what is known about a watched file at a point in time, a file is taken to have changed when any of it is different
+type FileState struct {
+	Path    string
+	Exists  bool
+	Size    int64
+	ModTime time.Time
+}
+
+// Snapshot is the state of every watched file, in the order they were given to Take
+type Snapshot []FileState
+
+// Return the current state of the files at the given paths. Files that don't exist are part of the snapshot too, so the snapshot changes when they are created
+func Take(paths []string) Snapshot {
+	snapshot := make(Snapshot, len(paths))
+	for i, path := range paths {
+		snapshot[i].Path = path
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		snapshot[i] = FileState{Path: path, Exists: true, Size: info.Size(), ModTime: info.ModTime()}
+	}
+	return snapshot
+}
+
+// Return the paths of the files whose state is different in the snapshot s and the earlier snapshot previous of the same files
+func (s Snapshot) Changed(previous Snapshot) []string {
+	changed := make([]string, 0)
+	for i, state := range s {
+		if i >= len(previous) || !state.ModTime.Equal(previous[i].ModTime) || state.Size != previous[i].Size || state.Exists != previous[i].Exists {
+			changed = append(changed, state.Path)
+		}
+	}
+	return changed
+}
+
+// Wait until at least one of the files of the snapshot changes, checking them every interval, and return their new snapshot along with the paths of the files that changed.
+// Editors often write a file in several steps, so the files are only taken to have changed once they stay the same for a whole interval. Waiting stops with the error of ctx if it is canceled
+func (o *Options) Wait(ctx context.Context, previous Snapshot) (Snapshot, []string, error) {
+	paths := make([]string, len(previous))
+	for i, state := range previous {
+		paths[i] = state.Path
+	}
+	interval := max(o.Interval, 10*time.Millisecond)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var pending Snapshot
+	for {
+		select {
+		case <-ctx.Done():
+			return previous, nil, ctx.Err()
+		case <-ticker.C:
+		}
+		current := Take(paths)
+		if pending != nil && len(current.Changed(pending)) == 0 {
+			return current, current.Changed(previous), nil
+		}
+		pending = nil
+		if len(current.Changed(previous)) > 0 {
+			pending = current
+		}
+	}
+}
+
+// Entry is a ranked word, as it is read from the json report of a run
+type Entry struct {
+	Word  string `json:"word"`
+	Count int    `json:"count"`
+}
+
+// Move is an entry of a ranking along with how it changed since the previous ranking
+type Move struct {
+	Entry
+	// Rank is the rank of the entry, starting from 1, and Previous is its rank in the previous ranking, or 0 if it wasn't in it
+	Rank     int
+	Previous int
+	// Delta is how much the count of the word changed, it is 0 for words that weren't in the previous ranking
+	Delta int
+}
+
+// Compare the ranking current with the ranking previous, and return a move for every entry of current along with the entries of previous that aren't in current anymore.
+// Every entry of the first ranking has no previous ranking to be compared with, so it keeps its rank when previous is nil
+func Diff(previous, current []Entry) ([]Move, []Entry) {
+	if previous == nil {
+		previous = current
+	}
+	ranks := make(map[string]int, len(previous))
+	for i, e := range previous {
+		ranks[e.Word] = i + 1
+	}
+
+	moves := make([]Move, len(current))
+	words := make(map[string]bool, len(current))
+	for i, e := range current {
+		moves[i] = Move{Entry: e, Rank: i + 1, Previous: ranks[e.Word]}
+		if rank := ranks[e.Word]; rank > 0 {
+			moves[i].Delta = e.Count - previous[rank-1].Count
+		}
+		words[e.Word] = true
+	}
+
+	dropped := make([]Entry, 0)
+	for _, e := range previous {
+		if !words[e.Word] {
+			dropped = append(dropped, e)
+		}
+	}
+	return moves, dropped
+}
+
+// Write the ranking as "word - freq" lines like the plain format, followed by how each word moved: ▲ and ▼ with the number of ranks it went up or down, "new" for words that just entered the ranking, and the change of its count.
+// The words that left the ranking are listed at the end
+func Write(w io.Writer, moves []Move, dropped []Entry) error {
+	for _, m := range moves {
+		line := fmt.Sprintf("%s - %d", m.Word, m.Count)
+		switch {
+		case m.Previous == 0:
+			line += " new"
+		case m.Previous > m.Rank:
+			line += fmt.Sprintf(" ▲%d", m.Previous-m.Rank)
+		case m.Previous < m.Rank:
+			line += fmt.Sprintf(" ▼%d", m.Rank-m.Previous)
+		}
+		if m.Delta != 0 {
+			line += fmt.Sprintf(" (%+d)", m.Delta)
+		}
+		_, err := fmt.Fprintln(w, line)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(dropped) > 0 {
+		words := make([]string, len(dropped))
+		for i, e := range dropped {
+			words[i] = e.Word
+		}
+		_, err := fmt.Fprintf(w, "left the ranking: %s\n", strings.Join(words, ", "))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	previous := []Entry{{"mr", 3}, {"darcy", 2}, {"lizzy", 1}}
+	current := []Entry{{"darcy", 5}, {"mr", 3}, {"bingley", 1}}
+	moves, dropped := Diff(previous, current)
+
+	want := []Move{
+		{Entry: Entry{"darcy", 5}, Rank: 1, Previous: 2, Delta: 3},
+		{Entry: Entry{"mr", 3}, Rank: 2, Previous: 1},
+		{Entry: Entry{"bingley", 1}, Rank: 3},
+	}
+	if !slices.Equal(moves, want) {
+		t.Errorf("got moves %+v, want %+v", moves, want)
+	}
+	if !slices.Equal(dropped, []Entry{{"lizzy", 1}}) {
+		t.Errorf("got dropped %v", dropped)
+	}
+
+	var out strings.Builder
+	err := Write(&out, moves, dropped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "darcy - 5 ▲1 (+3)\nmr - 3 ▼1\nbingley - 1 new\nleft the ranking: lizzy\n"
+	if out.String() != expected {
+		t.Errorf("got %q, want %q", out.String(), expected)
+	}
+
+	// The first ranking doesn't move
+	moves, dropped = Diff(nil, current)
+	for _, m := range moves {
+		if m.Previous != m.Rank || m.Delta != 0 {
+			t.Errorf("unexpected move %+v in the first ranking", m)
+		}
+	}
+	if len(dropped) > 0 {
+		t.Errorf("got dropped %v in the first ranking", dropped)
+	}
+}
+
+func TestWait(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "input.txt")
+	if err := os.WriteFile(path, []byte("darcy"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing.txt")
+	o := &Options{Enabled: true, Interval: 10 * time.Millisecond}
+	snapshot := Take([]string{path, missing})
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = os.WriteFile(path, []byte("darcy darcy"), 0o644)
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	snapshot, changed, err := o.Wait(ctx, snapshot)
+	if err != nil || !slices.Equal(changed, []string{path}) {
+		t.Fatalf("got %v, %v", changed, err)
+	}
+
+	// A file that is created is a change too, and waiting stops when ctx is canceled
+	if err := os.WriteFile(missing, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, changed, err = o.Wait(ctx, snapshot)
+	if err != nil || !slices.Equal(changed, []string{missing}) {
+		t.Fatalf("got %v, %v", changed, err)
+	}
+	cancel()
+	if _, _, err := o.Wait(ctx, snapshot); err == nil {
+		t.Error("expected the error of the canceled context")
+	}
+}